@@ -8,16 +8,20 @@ import (
 	"github.com/minor-industries/theheads/boss/day"
 	"github.com/minor-industries/theheads/boss/grid"
 	"github.com/minor-industries/theheads/boss/head_manager"
+	"github.com/minor-industries/theheads/boss/liveness"
 	"github.com/minor-industries/theheads/boss/scene"
 	"github.com/minor-industries/theheads/boss/services"
+	"github.com/minor-industries/theheads/config"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"io/fs"
+	"time"
 )
 
 type Boss struct {
 	Logger      *zap.Logger
-	Env         *cfg.Cfg
+	Env         *cfg.Cfg              // as loaded at startup
+	Live        *config.Live[cfg.Cfg] // for the settings which can be reloaded
 	Broker      *broker.Broker
 	Grid        *grid.Grid
 	Directory   *services.Directory
@@ -26,6 +30,7 @@ type Boss struct {
 	Frontend    fs.FS
 	DayDetector day.Detector
 	HeadManager *head_manager.HeadManager
+	Liveness    *liveness.Tracker
 }
 
 func (b *Boss) SetupMetrics() {
@@ -64,9 +69,13 @@ func (b *Boss) processHeartbeat(msg *schema.Heartbeat) {
 			logger.Warn("heartbeat: unknown instance")
 			return
 		}
+		// a head boss can't reach isn't any use to the scenes, so only acked beats count
+		start := time.Now()
 		if err := b.HeadManager.AckHeartbeat(head.URI(), msg.ID); err != nil {
 			logger.Warn("failed to ack heartbeat", zap.Error(err))
 			return
 		}
+		now := time.Now()
+		b.Liveness.Beat(msg.Component, msg.Instance, now.Sub(start), now)
 	}
 }
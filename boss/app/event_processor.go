@@ -1,9 +1,11 @@
 package app
 
 import (
+	"fmt"
 	geom2 "github.com/minor-industries/platform/common/geom"
 	"github.com/minor-industries/platform/schema"
 	"github.com/minor-industries/theheads/boss/rate_limiter"
+	"github.com/minor-industries/theheads/boss/watchdog"
 	"go.uber.org/zap"
 	"time"
 )
@@ -11,14 +13,28 @@ import (
 func (b *Boss) ProcessEvents() {
 	msgs := b.Broker.Subscribe()
 
-	for i := range msgs {
-		switch msg := i.(type) {
-		case *schema.MotionDetected:
-			b.processMotion(msg)
-		case *schema.FaceDetected:
-			b.processFaceDetected(msg)
-		case *schema.Heartbeat:
-			b.processHeartbeat(msg)
+	watchdog.Register("event-processor", time.Minute, nil)
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	last := "none" // the type of the last event, as state for the watchdog
+
+	for {
+		select {
+		case i := <-msgs:
+			last = fmt.Sprintf("%T", i)
+			watchdog.Feed("event-processor", last)
+
+			switch msg := i.(type) {
+			case *schema.MotionDetected:
+				b.processMotion(msg)
+			case *schema.FaceDetected:
+				b.processFaceDetected(msg)
+			case *schema.Heartbeat:
+				b.processHeartbeat(msg)
+			}
+		case <-ticker.C:
+			watchdog.Feed("event-processor", last) // quiet, but not stuck
 		}
 	}
 }
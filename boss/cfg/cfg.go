@@ -1,6 +1,9 @@
 package cfg
 
-import "time"
+import (
+	"github.com/minor-industries/theheads/config"
+	"time"
+)
 
 type Cfg struct {
 	ScenePath string
@@ -15,8 +18,51 @@ type Cfg struct {
 
 	Debug bool `envconfig:"optional"`
 
-	CheckInTime  time.Duration `envconfig:"default=500ms"`
-	FearfulCount int           `envconfig:"default=3"`
+	// watchdog diagnostics are logged when this is empty
+	WatchdogDumpPath string `envconfig:"optional"`
 
-	VoiceVolume int `envconfig:"default=-1"`
+	CheckInTime time.Duration `envconfig:"default=500ms"`
+
+	// a component is dead once it has missed this many heartbeats, which heads send
+	// every HeartbeatInterval
+	HeartbeatInterval time.Duration `envconfig:"default=1s"`
+	MissedHeartbeats  int           `envconfig:"default=5"`
+
+	// FearfulCount and the volume settings are reloaded on SIGHUP
+	FearfulCount int `envconfig:"default=3" reload:"true"`
+	VoiceVolume  int `envconfig:"default=-1" reload:"true"`
+
+	// VolumeSchedule adjusts VoiceVolume by time of day, e.g. 0h00m=-12;7h00m=0 for
+	// quieter nights. Each offset holds until the next entry.
+	VolumeSchedule []string `envconfig:"optional" reload:"true"`
+}
+
+var floodlightControllers = map[string]bool{
+	"on": true, "off": true, "day-night": true, "night": true, "presence": true, "random": true,
+}
+
+var dayDetectors = map[string]bool{
+	"time-based": true, "camera-feed": true, "solar-position": true, "solar-camera": true,
+}
+
+func (c *Cfg) Validate() error {
+	check := &config.Check{}
+	check.That(c.ScenePath != "", "ScenePath is required")
+	check.That(c.SpawnPeriod > 0, "SpawnPeriod must be positive")
+	check.That(
+		floodlightControllers[c.FloodlightController],
+		"unknown FloodlightController %q", c.FloodlightController,
+	)
+	check.That(
+		len(c.DayDetector) > 0 && dayDetectors[c.DayDetector[0]],
+		"unknown DayDetector %q", c.DayDetector,
+	)
+	check.That(c.CheckInTime > 0, "CheckInTime must be positive")
+	check.That(c.HeartbeatInterval > 0, "HeartbeatInterval must be positive")
+	check.That(c.MissedHeartbeats > 0, "MissedHeartbeats must be positive")
+	check.That(c.FearfulCount > 0, "FearfulCount must be positive")
+	check.That(c.VoiceVolume <= 0, "VoiceVolume is in dB and can't be above 0")
+	_, err := parseVolumeSchedule(c.VolumeSchedule)
+	check.That(err == nil, "VolumeSchedule: %v", err)
+	return check.Err()
 }
@@ -0,0 +1,67 @@
+package cfg
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type volumeStep struct {
+	at     time.Duration // since midnight
+	offset int
+}
+
+// parseVolumeSchedule reads entries like "0h00m=-12", sorted by time of day
+func parseVolumeSchedule(entries []string) ([]volumeStep, error) {
+	var steps []volumeStep
+	for _, entry := range entries {
+		at, offset, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("volume schedule entry %q should look like 22h00m=-6", entry)
+		}
+
+		d, err := time.ParseDuration(at)
+		if err != nil || d < 0 || d >= 24*time.Hour {
+			return nil, fmt.Errorf("invalid time of day %q", at)
+		}
+
+		db, err := strconv.Atoi(offset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid volume offset %q", offset)
+		}
+
+		steps = append(steps, volumeStep{at: d, offset: db})
+	}
+
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].at < steps[j].at
+	})
+	return steps, nil
+}
+
+// VolumeAt is the voice volume in dB for the time of day, VoiceVolume adjusted by the
+// VolumeSchedule entry in effect
+func (c *Cfg) VolumeAt(t time.Time) (int, error) {
+	steps, err := parseVolumeSchedule(c.VolumeSchedule)
+	if err != nil {
+		return 0, err
+	}
+	if len(steps) == 0 {
+		return c.VoiceVolume, nil
+	}
+
+	y, m, d := t.Date()
+	sinceMidnight := t.Sub(time.Date(y, m, d, 0, 0, 0, 0, t.Location()))
+
+	// before the first entry of the day the last one from yesterday still holds
+	step := steps[len(steps)-1]
+	for _, s := range steps {
+		if s.at <= sinceMidnight {
+			step = s
+		}
+	}
+
+	return c.VoiceVolume + step.offset, nil
+}
@@ -0,0 +1,32 @@
+package cfg
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestVolumeAt(t *testing.T) {
+	c := &Cfg{VoiceVolume: -3, VolumeSchedule: []string{"7h30m=0", "0h00m=-12", "22h00m=-6"}}
+	at := func(hour, min int) int {
+		v, err := c.VolumeAt(time.Date(2023, 8, 28, hour, min, 0, 0, time.Local))
+		require.NoError(t, err)
+		return v
+	}
+
+	assert.Equal(t, -15, at(0, 0))
+	assert.Equal(t, -15, at(7, 29))
+	assert.Equal(t, -3, at(12, 0))
+	assert.Equal(t, -9, at(23, 59))
+
+	c.VolumeSchedule = []string{"22h00m=-6"}
+	assert.Equal(t, -9, at(3, 0)) // still last night's entry
+
+	c.VolumeSchedule = nil
+	assert.Equal(t, -3, at(3, 0))
+
+	c.VolumeSchedule = []string{"25h00m=-6"}
+	_, err := c.VolumeAt(time.Now())
+	assert.Error(t, err)
+}
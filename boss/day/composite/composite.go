@@ -0,0 +1,72 @@
+package composite
+
+import (
+	"github.com/minor-industries/theheads/boss/day"
+	"sync"
+	"time"
+)
+
+// Detector only switches between day and night once all of its detectors have agreed
+// on the new state for the hold duration. Until then it keeps reporting the old state,
+// so a short burst of light at one camera doesn't flip the whole installation.
+type Detector struct {
+	detectors []day.Detector
+	hold      time.Duration
+
+	lock   sync.Mutex
+	day    bool
+	agreed time.Time // when the detectors started agreeing on the opposite state
+}
+
+// NewDetector starts out in whatever state the first detector reports
+func NewDetector(hold time.Duration, detectors ...day.Detector) *Detector {
+	return &Detector{
+		detectors: detectors,
+		hold:      hold,
+		day:       detectors[0].IsDay(),
+	}
+}
+
+func (d *Detector) Run() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		d.update(time.Now())
+	}
+}
+
+func (d *Detector) update(now time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if !d.allSay(!d.day) {
+		d.agreed = time.Time{}
+		return
+	}
+
+	if d.agreed.IsZero() {
+		d.agreed = now
+	}
+
+	if now.Sub(d.agreed) >= d.hold {
+		d.day = !d.day
+		d.agreed = time.Time{}
+	}
+}
+
+func (d *Detector) allSay(isDay bool) bool {
+	for _, detector := range d.detectors {
+		if detector.IsDay() != isDay {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *Detector) IsDay() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.day
+}
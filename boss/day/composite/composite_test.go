@@ -0,0 +1,68 @@
+package composite
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type fixed struct{ day bool }
+
+func (f *fixed) IsDay() bool { return f.day }
+
+func TestDetector(t *testing.T) {
+	type step struct {
+		at       time.Duration // since the start
+		solar    bool
+		camera   bool
+		expected bool
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"flips once both agree for the hold", []step{
+			{0, false, false, false},
+			{time.Minute, true, true, false},
+			{4 * time.Minute, true, true, false},
+			{6 * time.Minute, true, true, true},
+		}},
+		{"a headlight doesn't flip it", []step{
+			{0, false, false, false},
+			{time.Minute, false, true, false},
+			{10 * time.Minute, false, true, false},
+		}},
+		{"disagreeing restarts the hold", []step{
+			{0, false, false, false},
+			{time.Minute, true, true, false},
+			{4 * time.Minute, true, false, false},
+			{5 * time.Minute, true, true, false},
+			{9 * time.Minute, true, true, false},
+			{10 * time.Minute, true, true, true},
+		}},
+		{"holds the new state", []step{
+			{0, true, true, true},
+			{time.Minute, false, false, true},
+			{6 * time.Minute, false, false, false},
+			{7 * time.Minute, true, false, false},
+			{20 * time.Minute, true, false, false},
+		}},
+	}
+
+	start := time.Date(2023, 8, 30, 19, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solar := &fixed{day: tt.steps[0].solar}
+			camera := &fixed{day: tt.steps[0].camera}
+			d := NewDetector(5*time.Minute, solar, camera)
+
+			for _, s := range tt.steps {
+				solar.day, camera.day = s.solar, s.camera
+				d.update(start.Add(s.at))
+				assert.Equal(t, s.expected, d.IsDay(), "at %s", s.at)
+			}
+		})
+	}
+}
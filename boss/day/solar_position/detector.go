@@ -0,0 +1,106 @@
+package solar_position
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mark is a solar event plus an offset, e.g. "civil-dusk+30m" or "sunrise-15m"
+type Mark struct {
+	Event  Event
+	Offset time.Duration
+}
+
+func ParseMark(s string) (Mark, error) {
+	// event names contain dashes, so split on the name rather than the sign
+	var event Event
+	var offset string
+	found := false
+	for name, e := range eventNames {
+		if strings.HasPrefix(s, name) {
+			event, offset, found = e, s[len(name):], true
+		}
+	}
+
+	if !found {
+		return Mark{}, fmt.Errorf("unknown solar event: %s", s)
+	}
+
+	var d time.Duration
+	if offset != "" {
+		var err error
+		d, err = time.ParseDuration(offset)
+		if err != nil {
+			return Mark{}, fmt.Errorf("invalid offset in %s: %w", s, err)
+		}
+	}
+
+	return Mark{Event: event, Offset: d}, nil
+}
+
+type Detector struct {
+	lat, lon   float64
+	start, end Mark
+}
+
+// NewDetector takes latitude, longitude (degrees, east positive) and the marks at which
+// the day starts and ends, e.g. "40.78", "-119.21", "civil-dawn", "civil-dusk+30m"
+func NewDetector(args ...string) *Detector {
+	if len(args) != 4 {
+		panic("solar-position detector needs lat, lon, start and end")
+	}
+
+	lat, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		panic(err)
+	}
+
+	lon, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		panic(err)
+	}
+
+	start, err := ParseMark(args[2])
+	if err != nil {
+		panic(err)
+	}
+
+	end, err := ParseMark(args[3])
+	if err != nil {
+		panic(err)
+	}
+
+	return &Detector{
+		lat:   lat,
+		lon:   lon,
+		start: start,
+		end:   end,
+	}
+}
+
+func (d *Detector) IsDay() bool {
+	return d.isDay(time.Now())
+}
+
+func (d *Detector) isDay(now time.Time) bool {
+	// EventTime takes the day from its argument's location. Local mean solar time flips
+	// to the next day around the observer's midnight, whatever zone this process runs in.
+	solar := now.In(time.FixedZone("solar", int(d.lon*240)))
+
+	start, ok, alwaysAbove := EventTime(solar, d.lat, d.lon, d.start.Event)
+	if !ok {
+		return alwaysAbove
+	}
+
+	end, ok, alwaysAbove := EventTime(solar, d.lat, d.lon, d.end.Event)
+	if !ok {
+		return alwaysAbove
+	}
+
+	start = start.Add(d.start.Offset)
+	end = end.Add(d.end.Offset)
+
+	return now.After(start) && now.Before(end)
+}
@@ -0,0 +1,114 @@
+package solar_position
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	j2000      = 2451545.0 // julian date of 2000-01-01 12:00 UTC
+	unixEpochJ = 2440587.5 // julian date of 1970-01-01 00:00 UTC
+	obliquity  = 23.4397   // degrees
+
+	// solar elevations (degrees) at which the various events happen
+	elevationSunrise = -0.833 // accounts for refraction and the solar disc
+	elevationCivil   = -6.0
+)
+
+func rad(deg float64) float64 {
+	return deg * math.Pi / 180.0
+}
+
+func deg(rad float64) float64 {
+	return rad * 180.0 / math.Pi
+}
+
+func toJulian(t time.Time) float64 {
+	return float64(t.UnixNano())/float64(24*time.Hour) + unixEpochJ
+}
+
+func fromJulian(j float64) time.Time {
+	ns := (j - unixEpochJ) * float64(24*time.Hour)
+	return time.Unix(0, int64(ns))
+}
+
+// solarNoon returns the julian date of solar transit and the solar declination
+// (radians) on the day containing localNoon, for an observer at longitude lon
+// (degrees, east positive).
+//
+// See https://en.wikipedia.org/wiki/Sunrise_equation
+func solarNoon(localNoon time.Time, lon float64) (float64, float64) {
+	n := math.Round(toJulian(localNoon) - j2000 + lon/360.0)
+	jStar := n - lon/360.0
+
+	m := math.Mod(357.5291+0.98560028*jStar, 360)
+	c := 1.9148*math.Sin(rad(m)) + 0.0200*math.Sin(rad(2*m)) + 0.0003*math.Sin(rad(3*m))
+	lambda := math.Mod(m+c+180+102.9372, 360)
+
+	transit := j2000 + jStar + 0.0053*math.Sin(rad(m)) - 0.0069*math.Sin(rad(2*lambda))
+	declination := math.Asin(math.Sin(rad(lambda)) * math.Sin(rad(obliquity)))
+
+	return transit, declination
+}
+
+// hourAngle returns half of the time (as a fraction of a day) that the sun spends above
+// the given elevation. ok is false if the sun never crosses that elevation on this day, in
+// which case alwaysAbove tells which side of it the sun stays on.
+func hourAngle(lat, declination, elevation float64) (days float64, ok bool, alwaysAbove bool) {
+	cosW := (math.Sin(rad(elevation)) - math.Sin(rad(lat))*math.Sin(declination)) /
+		(math.Cos(rad(lat)) * math.Cos(declination))
+
+	switch {
+	case cosW > 1:
+		return 0, false, false
+	case cosW < -1:
+		return 0, false, true
+	}
+
+	return deg(math.Acos(cosW)) / 360.0, true, false
+}
+
+type Event int
+
+const (
+	CivilDawn Event = iota
+	Sunrise
+	Sunset
+	CivilDusk
+)
+
+var eventNames = map[string]Event{
+	"civil-dawn": CivilDawn,
+	"sunrise":    Sunrise,
+	"sunset":     Sunset,
+	"civil-dusk": CivilDusk,
+}
+
+// EventTime returns the time of the event on the calendar day of `date` (in date's
+// location). ok is false in polar regions when the event doesn't happen that day, and
+// alwaysAbove then reports whether the sun stays above the event's elevation all day.
+func EventTime(
+	date time.Time,
+	lat, lon float64,
+	event Event,
+) (t time.Time, ok bool, alwaysAbove bool) {
+	localNoon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, date.Location())
+	transit, declination := solarNoon(localNoon, lon)
+
+	elevation := elevationSunrise
+	if event == CivilDawn || event == CivilDusk {
+		elevation = elevationCivil
+	}
+
+	w, ok, alwaysAbove := hourAngle(lat, declination, elevation)
+	if !ok {
+		return time.Time{}, false, alwaysAbove
+	}
+
+	switch event {
+	case CivilDawn, Sunrise:
+		return fromJulian(transit - w).In(date.Location()), true, false
+	default:
+		return fromJulian(transit + w).In(date.Location()), true, false
+	}
+}
@@ -0,0 +1,77 @@
+package solar_position
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestEventTime(t *testing.T) {
+	// Black Rock City, end of August. Reference times from the NOAA solar calculator.
+	loc := time.FixedZone("PDT", -7*60*60)
+	date := time.Date(2023, 8, 30, 0, 0, 0, 0, loc)
+	lat, lon := 40.786, -119.206
+
+	check := func(event Event, hour, minute int) {
+		tm, ok, _ := EventTime(date, lat, lon, event)
+		require.True(t, ok)
+		expected := time.Date(2023, 8, 30, hour, minute, 0, 0, loc)
+		assert.InDelta(t, 0, tm.Sub(expected).Minutes(), 2, "%s", tm)
+	}
+
+	check(CivilDawn, 5, 53)
+	check(Sunrise, 6, 21)
+	check(Sunset, 19, 33)
+	check(CivilDusk, 20, 1)
+}
+
+func TestPolar(t *testing.T) {
+	date := time.Date(2023, 6, 21, 0, 0, 0, 0, time.UTC)
+
+	_, ok, alwaysAbove := EventTime(date, 78.22, 15.65, Sunrise) // svalbard
+	assert.False(t, ok)
+	assert.True(t, alwaysAbove)
+
+	_, ok, alwaysAbove = EventTime(date, -78.22, 15.65, Sunrise)
+	assert.False(t, ok)
+	assert.False(t, alwaysAbove)
+}
+
+func TestParseMark(t *testing.T) {
+	m, err := ParseMark("civil-dusk+30m")
+	require.NoError(t, err)
+	assert.Equal(t, Mark{Event: CivilDusk, Offset: 30 * time.Minute}, m)
+
+	m, err = ParseMark("sunrise-15m")
+	require.NoError(t, err)
+	assert.Equal(t, Mark{Event: Sunrise, Offset: -15 * time.Minute}, m)
+
+	m, err = ParseMark("civil-dawn")
+	require.NoError(t, err)
+	assert.Equal(t, Mark{Event: CivilDawn}, m)
+
+	_, err = ParseMark("moonrise")
+	assert.Error(t, err)
+}
+
+func TestIsDay(t *testing.T) {
+	loc := time.FixedZone("PDT", -7*60*60)
+	d := NewDetector("40.786", "-119.206", "civil-dawn", "civil-dusk+30m")
+
+	assert.False(t, d.isDay(time.Date(2023, 8, 30, 5, 30, 0, 0, loc)))
+	assert.True(t, d.isDay(time.Date(2023, 8, 30, 6, 0, 0, 0, loc)))
+	assert.True(t, d.isDay(time.Date(2023, 8, 30, 20, 20, 0, 0, loc)))
+	assert.False(t, d.isDay(time.Date(2023, 8, 30, 20, 45, 0, 0, loc)))
+}
+
+func TestIsDayInUTC(t *testing.T) {
+	// same instants as TestIsDay, seen from a process running in UTC, where the evening
+	// ones fall on the next calendar day
+	d := NewDetector("40.786", "-119.206", "civil-dawn", "civil-dusk+30m")
+
+	assert.False(t, d.isDay(time.Date(2023, 8, 30, 12, 30, 0, 0, time.UTC)))
+	assert.True(t, d.isDay(time.Date(2023, 8, 30, 13, 0, 0, 0, time.UTC)))
+	assert.True(t, d.isDay(time.Date(2023, 8, 31, 3, 20, 0, 0, time.UTC)))
+	assert.False(t, d.isDay(time.Date(2023, 8, 31, 3, 45, 0, 0, time.UTC)))
+}
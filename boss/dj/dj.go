@@ -2,12 +2,14 @@ package dj
 
 import (
 	"context"
+	"fmt"
 	"github.com/minor-industries/theheads/boss/app"
 	"github.com/minor-industries/theheads/boss/grid"
 	"github.com/minor-industries/theheads/boss/head_manager"
 	"github.com/minor-industries/theheads/boss/scene"
 	"github.com/minor-industries/theheads/boss/services"
 	"github.com/minor-industries/theheads/boss/util"
+	"github.com/minor-industries/theheads/boss/watchdog"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
@@ -61,8 +63,6 @@ type DJ struct {
 	AllScenes   map[string]SceneConfig
 	Boss        *app.Boss
 
-	FloodlightController func() bool
-
 	interrupted *atomic.String
 }
 
@@ -78,15 +78,27 @@ func NewDJ(
 		Directory:   boss.Directory,
 		AllScenes:   allScenes,
 
-		FloodlightController: boss.FloodlightControl,
-
 		interrupted: atomic.NewString(""),
 
 		Boss: boss,
 	}
 }
 
+// LiveHeads is the heads still sending heartbeats, in scene order. Scenes should use it
+// rather than the scene's heads so they don't wait on heads that are down.
+func (dj *DJ) LiveHeads() []*scene.Head {
+	var result []*scene.Head
+	for _, head := range dj.Scene.Heads {
+		if dj.Boss.Liveness.Alive("head", head.Name) {
+			result = append(result, head)
+		}
+	}
+	return result
+}
+
 func (dj *DJ) RunScenes() {
+	watchdog.Register("dj", 2*time.Minute, nil)
+
 	sceneNumber := 1
 
 	for _, sceneName := range dj.Scene.StartupScenes {
@@ -113,6 +125,7 @@ func (dj *DJ) RunScenes() {
 func (dj *DJ) runScene(sceneName string, sceneNumber int) {
 	logger := dj.Logger.With(zap.String("scene_name", sceneName), zap.Int("scene_number", sceneNumber))
 	logger.Info("Running Scene")
+	watchdog.Feed("dj", fmt.Sprintf("starting scene %d: %s", sceneNumber, sceneName))
 	done := util.NewBroadcastCloser()
 	defer done.Close()
 
@@ -0,0 +1,253 @@
+package floodlights
+
+import (
+	"context"
+	gen "github.com/minor-industries/protobuf/gen/go/heads"
+	"github.com/minor-industries/theheads/boss/day"
+	"github.com/minor-industries/theheads/boss/grid"
+	"github.com/minor-industries/theheads/boss/head_manager"
+	"github.com/minor-industries/theheads/boss/scene"
+	"go.uber.org/zap"
+	"math/rand"
+	"time"
+)
+
+const (
+	evaluatePeriod = 5 * time.Second
+	resendPeriod   = time.Minute // cameras forget their floodlight state when they restart
+	randomPeriod   = 15 * time.Minute
+
+	defaultDutyWindow     = time.Hour
+	defaultPresenceRadius = 3.0
+
+	// a light that hits its duty cycle limit stays off until it's this far under it
+	dutyCycleMargin = 0.05
+)
+
+type sample struct {
+	t  time.Time
+	on bool
+}
+
+type light struct {
+	on      bool
+	onSince time.Time
+	sent    time.Time
+
+	samples []sample // for the duty cycle
+	limited bool     // off for the duty cycle
+
+	random       bool
+	randomRolled time.Time
+}
+
+type Controller struct {
+	logger        *zap.Logger
+	scene         *scene.Scene
+	grid          *grid.Grid
+	dayDetector   day.Detector
+	headManager   *head_manager.HeadManager
+	defaultPolicy scene.FloodlightPolicy
+
+	lights map[string]*light
+}
+
+// NewController takes the default mode from the boss FLOODLIGHT_CONTROLLER setting,
+// which the scene and its stands can override.
+func NewController(
+	logger *zap.Logger,
+	defaultMode string,
+	sc *scene.Scene,
+	g *grid.Grid,
+	dayDetector day.Detector,
+	headManager *head_manager.HeadManager,
+) *Controller {
+	if defaultMode == "day-night" {
+		defaultMode = "night"
+	}
+
+	defaultPolicy := sc.Floodlight.Merge(scene.FloodlightPolicy{})
+	defaultPolicy = defaultPolicy.Merge(scene.FloodlightPolicy{
+		Mode:              defaultMode,
+		DutyWindowSeconds: ptr(defaultDutyWindow.Seconds()),
+		PresenceRadius:    ptr(defaultPresenceRadius),
+	})
+
+	return &Controller{
+		logger:        logger,
+		scene:         sc,
+		grid:          g,
+		dayDetector:   dayDetector,
+		headManager:   headManager,
+		defaultPolicy: defaultPolicy,
+		lights:        map[string]*light{},
+	}
+}
+
+func (c *Controller) Run() {
+	ticker := time.NewTicker(evaluatePeriod)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.evaluate(time.Now())
+	}
+}
+
+func (c *Controller) evaluate(now time.Time) {
+	isDay := c.dayDetector.IsDay()
+	count := 0
+
+	for _, stand := range c.scene.Stands {
+		if stand.Disabled {
+			continue
+		}
+
+		policy := stand.Floodlight.Merge(c.defaultPolicy)
+		near := c.someoneNear(stand, value(policy.PresenceRadius))
+
+		for _, camera := range stand.Cameras {
+			uri := camera.URI()
+			l, ok := c.lights[uri]
+			if !ok {
+				l = &light{}
+				c.lights[uri] = l
+			}
+
+			want := c.want(l, policy, now, isDay, near)
+			on := l.constrain(policy, now, want)
+			if on {
+				count++
+			}
+
+			if on != l.on || now.Sub(l.sent) > resendPeriod {
+				if c.send(uri, on) {
+					l.sent = now
+				}
+			}
+
+			if on && !l.on {
+				l.onSince = now
+			}
+			l.on = on
+			l.record(now, on, policy)
+		}
+	}
+
+	gFloodlightsOn.Set(float64(count))
+}
+
+func (c *Controller) someoneNear(stand *scene.Stand, radius float64) bool {
+	fp, dist := c.grid.ClosestFocalPointTo(stand.M.Translation())
+	return fp != nil && dist < radius
+}
+
+func (c *Controller) want(
+	l *light,
+	policy scene.FloodlightPolicy,
+	now time.Time,
+	isDay bool,
+	near bool,
+) bool {
+	switch policy.Mode {
+	case "on":
+		return true
+	case "off":
+		return false
+	case "night":
+		return !isDay
+	case "presence":
+		return !isDay && near
+	case "random":
+		if now.Sub(l.randomRolled) > randomPeriod {
+			l.random = rand.Float64() < 0.5
+			l.randomRolled = now
+		}
+		return l.random
+	default:
+		c.logger.Error("unknown floodlight mode", zap.String("mode", policy.Mode))
+		return false
+	}
+}
+
+// constrain applies the minimum on-time and the duty cycle limit; the duty cycle wins
+func (l *light) constrain(policy scene.FloodlightPolicy, now time.Time, want bool) bool {
+	minOn := time.Duration(value(policy.MinOnSeconds) * float64(time.Second))
+	if !want && l.on && now.Sub(l.onSince) < minOn {
+		want = true
+	}
+
+	maxDuty := value(policy.MaxDutyCycle)
+	if maxDuty <= 0 {
+		l.limited = false
+		return want
+	}
+
+	duty := l.dutyCycle()
+	switch {
+	case duty >= maxDuty:
+		l.limited = true
+	case duty < maxDuty-dutyCycleMargin:
+		l.limited = false
+	}
+
+	return want && !l.limited
+}
+
+func (l *light) record(now time.Time, on bool, policy scene.FloodlightPolicy) {
+	l.samples = append(l.samples, sample{t: now, on: on})
+
+	window := time.Duration(value(policy.DutyWindowSeconds) * float64(time.Second))
+	idx := 0
+	for idx < len(l.samples) && now.Sub(l.samples[idx].t) > window {
+		idx++
+	}
+	l.samples = l.samples[idx:]
+}
+
+func (l *light) dutyCycle() float64 {
+	if len(l.samples) == 0 {
+		return 0
+	}
+
+	on := 0
+	for _, s := range l.samples {
+		if s.on {
+			on++
+		}
+	}
+	return float64(on) / float64(len(l.samples))
+}
+
+func (c *Controller) send(cameraURI string, state bool) bool {
+	logger := c.logger.With(zap.String("camera", cameraURI), zap.Bool("state", state))
+
+	conn, err := c.headManager.GetConn(cameraURI)
+	if err != nil {
+		// not checked in yet
+		logger.Debug("error getting camera connection", zap.Error(err))
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), evaluatePeriod)
+	defer cancel()
+
+	_, err = gen.NewFloodlightClient(conn.Conn).SetState(ctx, &gen.SetStateIn{State: state})
+	if err != nil {
+		logger.Error("error setting floodlight state", zap.Error(err))
+		return false
+	}
+
+	return true
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+// value is zero for an unset field
+func value(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,53 @@
+package floodlights
+
+import (
+	"github.com/minor-industries/theheads/boss/scene"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDutyCycleHysteresis(t *testing.T) {
+	policy := scene.FloodlightPolicy{
+		MaxDutyCycle:      ptr(0.5),
+		DutyWindowSeconds: ptr(time.Hour.Seconds()),
+	}
+
+	l := &light{}
+	now := time.Date(2023, 8, 30, 22, 0, 0, 0, time.UTC)
+
+	var states []bool
+	for i := 0; i < 1440; i++ { // two hours of samples, wanting the light on throughout
+		now = now.Add(evaluatePeriod)
+		on := l.constrain(policy, now, true)
+		l.on = on
+		l.record(now, on, policy)
+		states = append(states, on)
+	}
+
+	switches := 0
+	for i := 1; i < len(states); i++ {
+		if states[i] != states[i-1] {
+			switches++
+		}
+	}
+
+	// without hysteresis it would switch every sample once it reached the limit
+	assert.Less(t, switches, 100)
+	assert.InDelta(t, 0.5, l.dutyCycle(), dutyCycleMargin)
+}
+
+func TestMergeBackToZero(t *testing.T) {
+	sc := scene.FloodlightPolicy{
+		Mode:         "night",
+		MinOnSeconds: ptr(60),
+		MaxDutyCycle: ptr(0.5),
+	}
+	stand := &scene.FloodlightPolicy{MaxDutyCycle: ptr(0)}
+
+	policy := stand.Merge(sc)
+	assert.Equal(t, "night", policy.Mode)
+	assert.Equal(t, 60.0, value(policy.MinOnSeconds))
+	assert.NotNil(t, policy.MaxDutyCycle)
+	assert.Equal(t, 0.0, value(policy.MaxDutyCycle))
+}
@@ -0,0 +1,14 @@
+package floodlights
+
+import (
+	"github.com/minor-industries/platform/common/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	gFloodlightsOn = metrics.SimpleGauge(
+		prometheus.DefaultRegisterer,
+		"boss",
+		"floodlights_on",
+	)
+)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/minor-industries/platform/schema"
 	"github.com/minor-industries/theheads/boss/frontend/reaper"
+	livenessschema "github.com/minor-industries/theheads/boss/liveness/schema"
 	"github.com/minor-industries/theheads/boss/scene"
 	"syscall/js"
 	"time"
@@ -16,6 +17,8 @@ type Empty struct{}
 
 type Draw struct {
 	heads       map[string]js.Value
+	headCircles map[string]js.Value
+	standHealth map[string]js.Value
 	cameras     map[string]js.Value
 	focalPoints map[string]js.Value
 
@@ -30,6 +33,8 @@ func New(svgRoot js.Value, sc *scene.Scene) *Draw {
 		svgRoot:     svgRoot,
 		scene:       sc,
 		heads:       map[string]js.Value{},
+		headCircles: map[string]js.Value{},
+		standHealth: map[string]js.Value{},
 		cameras:     map[string]js.Value{},
 		focalPoints: map[string]js.Value{},
 	}
@@ -67,6 +72,55 @@ func (h *Draw) MotionDetected(req *schema.MotionDetected, resp *Empty) error {
 	return nil
 }
 
+// Liveness greys out heads which have stopped sending heartbeats, and shows how each
+// stand is doing
+func (h *Draw) Liveness(req *livenessschema.Report, resp *Empty) error {
+	heads := map[string]livenessschema.Status{}
+	for _, st := range req.Statuses {
+		if st.Component == "head" {
+			heads[st.Instance] = st
+		}
+	}
+
+	for name, circle := range h.headCircles {
+		fill := "#806"
+		if st, ok := heads[name]; ok && !st.Alive {
+			fill = "#444"
+		}
+		circle.Call("attr", map[string]interface{}{"fill": fill})
+	}
+
+	for _, stand := range h.scene.Stands {
+		text, ok := h.standHealth[stand.Name]
+		if !ok {
+			continue
+		}
+
+		label, color := "", "darkgrey"
+		var rtt time.Duration
+		for _, head := range stand.Heads {
+			st, ok := heads[head.Name]
+			if !ok {
+				continue
+			}
+			if !st.Alive {
+				label, color = fmt.Sprintf("%s down", head.Name), "red"
+				break
+			}
+			if st.RTT > rtt {
+				rtt = st.RTT
+			}
+			label = fmt.Sprintf("rtt %dms", rtt.Milliseconds())
+		}
+
+		text.Call("text", label)
+		text.Call("attr", map[string]interface{}{"fill": color})
+	}
+
+	resp = &Empty{}
+	return nil
+}
+
 func (h *Draw) deleteFocalPoints(req *schema.FocalPoints) {
 	allKeys := map[string]bool{}
 	for _, fp := range req.FocalPoints {
@@ -151,8 +205,18 @@ func (h *Draw) drawStand(stand *scene.Stand) {
 		text.Call("center", 0, -1.4*radius)
 
 		h.heads[head.Name] = g2
+		h.headCircles[head.Name] = circle
 	}
 
+	health := moved.Call("text", "").Call("attr", map[string]interface{}{
+		"fill": "darkgrey",
+	}).Call("font", map[string]interface{}{
+		"size": 0.08,
+	})
+	health.Call("scale", 1, -1, 0, 0)
+	health.Call("center", 0, -1.4*radius-0.12)
+	h.standHealth[stand.Name] = health
+
 	for _, camera := range stand.Cameras {
 		g2 := rotated.Call("group")
 		g2.Call("move", camera.Pos.X, camera.Pos.Y)
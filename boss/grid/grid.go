@@ -6,6 +6,7 @@ import (
 	"github.com/minor-industries/platform/common/geom"
 	"github.com/minor-industries/platform/schema"
 	"github.com/minor-industries/theheads/boss/scene"
+	"github.com/minor-industries/theheads/boss/watchdog"
 	"go.uber.org/zap"
 	"gonum.org/v1/gonum/mat"
 	"math"
@@ -179,9 +180,12 @@ func (g *Grid) traceSteps(layer *mat.Dense, posX, posY, dX, dY float64, steps in
 }
 
 func (g *Grid) Start() {
+	watchdog.Register("grid", time.Minute, nil)
+
 	time.Sleep(1000 * time.Millisecond)
 	for {
 		time.Sleep(g.spawnPeriod)
+		watchdog.Feed("grid", g.spawnPeriod)
 		g.withLock(func() {
 			g.maybeSpawnFocalPoint()
 		})
@@ -3,13 +3,31 @@ package head_manager
 import (
 	"context"
 	"github.com/minor-industries/protobuf/gen/go/heads"
+	"github.com/minor-industries/theheads/boss/scene"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"sync"
 	"time"
 )
 
+// voice priorities; a higher priority clip cuts off a lower one that's playing
+const (
+	PriorityChatter = 0
+	PriorityScream  = 10
+)
+
+// errorPause is the least Say takes when a line couldn't be played, so a conversation
+// with a failing head doesn't spin
+const errorPause = 2 * time.Second
+
+// MethodSetActor is the grpc method to Schedule for SetActor
+const MethodSetActor = "/heads.head/set_actor"
+
 func (h *HeadManager) GetConn(URI string) (*Connection, error) {
 	h.lock.Lock()
 	defer h.lock.Unlock()
@@ -22,17 +40,33 @@ func (h *HeadManager) GetConn(URI string) (*Connection, error) {
 	return conn, nil
 }
 
+// Motion is how fast a head turns, in degrees per second (squared). The head clamps it
+// to its own limits, and zero values leave the head's defaults.
+type Motion struct {
+	Speed        float64
+	Acceleration float64
+}
+
+var (
+	MotionDefault = Motion{}
+	MotionCreep   = Motion{Speed: 20, Acceleration: 20}
+	MotionSnap    = Motion{Speed: 360, Acceleration: 1440}
+)
+
 func (h *HeadManager) SetTarget(
 	ctx context.Context,
 	headURI string,
 	theta float64,
+	motion Motion,
 ) (*heads.HeadState, error) {
 	client, err := h.GetConn(headURI)
 	if err != nil {
 		return nil, errors.Wrap(err, "get client")
 	}
 	return heads.NewHeadClient(client.Conn).SetTarget(ctx, &heads.SetTargetIn{
-		Theta: theta,
+		Theta:        theta,
+		Speed:        motion.Speed,
+		Acceleration: motion.Acceleration,
 	})
 }
 
@@ -40,13 +74,44 @@ func (h *HeadManager) SetActor(
 	ctx context.Context,
 	headURI string,
 	actor string,
+) (*heads.HeadState, error) {
+	return h.SetActorParams(ctx, headURI, actor, nil)
+}
+
+// SetActorParams tunes the actor as well, see the head's actors for the params each takes
+func (h *HeadManager) SetActorParams(
+	ctx context.Context,
+	headURI string,
+	actor string,
+	params map[string]float64,
 ) (*heads.HeadState, error) {
 	client, err := h.GetConn(headURI)
 	if err != nil {
 		return nil, errors.Wrap(err, "get client")
 	}
 	return heads.NewHeadClient(client.Conn).SetActor(ctx, &heads.SetActorIn{
-		Actor: actor,
+		Actor:  actor,
+		Params: params,
+	})
+}
+
+// RunTimeline has the head move through the keyframes from start on its own clock, so
+// heads given the same start move together
+func (h *HeadManager) RunTimeline(
+	ctx context.Context,
+	headURI string,
+	name string,
+	start time.Time,
+	keyframes []*heads.Keyframe,
+) (*heads.HeadState, error) {
+	client, err := h.GetConn(headURI)
+	if err != nil {
+		return nil, errors.Wrap(err, "get client")
+	}
+	return heads.NewHeadClient(client.Conn).RunTimeline(ctx, &heads.RunTimelineIn{
+		Name:      name,
+		Start:     timestamppb.New(start),
+		Keyframes: keyframes,
 	})
 }
 
@@ -65,32 +130,56 @@ func (h *HeadManager) Say(
 		time.Sleep(5 * time.Second) // Sleep for length of some typical text
 	} else {
 		client := heads.NewVoicesClient(conn.Conn)
-		_, err = client.Play(ctx, &heads.PlayIn{Sound: sound})
+		_, err = client.Play(ctx, &heads.PlayIn{Sound: sound, Priority: PriorityChatter})
 		if err != nil {
 			logger.Error("error playing sound", zap.Error(err), zap.String("sound", sound))
-			// TODO: might need dj.Sleep()
-			time.Sleep(5 * time.Second) // Sleep for length of some typical text
+			// keep the conversation paced by whatever the head is busy with instead, and
+			// by at least errorPause when the head can't tell us
+			until := time.Now().Add(errorPause)
+			waitUntilQuiet(ctx, client)
+			sleepUntil(ctx, until)
 		}
 	}
 }
 
-func (h *HeadManager) SetVolume(
-	ctx context.Context,
-	logger *zap.Logger,
-	headURI string,
-) {
-	conn, err := h.GetConn(headURI)
-	if err != nil {
-		logger.Error("error fetching head connection", zap.Error(err))
-	} else {
-		client := heads.NewVoicesClient(conn.Conn)
-		_, err = client.SetVolume(ctx, &heads.SetVolumeIn{VolDb: int32(h.env.VoiceVolume)})
+func sleepUntil(ctx context.Context, t time.Time) {
+	select {
+	case <-time.After(time.Until(t)):
+	case <-ctx.Done():
+	}
+}
+
+// waitUntilQuiet waits until the head has nothing playing or queued
+func waitUntilQuiet(ctx context.Context, client heads.VoicesClient) {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		st, err := client.Status(ctx, &heads.Empty{})
 		if err != nil {
-			logger.Error("error setting volume", zap.Error(err))
+			return
+		}
+		if !st.Playing && len(st.Queued) == 0 {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
 		}
 	}
 }
 
+func (h *HeadManager) SetVolume(ctx context.Context, headURI string, volDb int32) error {
+	conn, err := h.GetConn(headURI)
+	if err != nil {
+		return errors.Wrap(err, "get conn")
+	}
+	_, err = heads.NewVoicesClient(conn.Conn).SetVolume(ctx, &heads.SetVolumeIn{VolDb: volDb})
+	return errors.Wrap(err, "set volume")
+}
+
 func (h *HeadManager) SayRandom(ctx context.Context, headURI string) error {
 	conn, err := h.GetConn(headURI)
 	if err != nil {
@@ -101,6 +190,21 @@ func (h *HeadManager) SayRandom(ctx context.Context, headURI string) error {
 	return err
 }
 
+// SayTagged plays a random clip with the tag, falling back to any clip on heads which
+// have nothing tagged that way
+func (h *HeadManager) SayTagged(ctx context.Context, headURI string, tag string, priority int32) error {
+	conn, err := h.GetConn(headURI)
+	if err != nil {
+		return errors.Wrap(err, "get conn")
+	}
+	client := heads.NewVoicesClient(conn.Conn)
+	_, err = client.RandomByTag(ctx, &heads.RandomByTagIn{Tag: tag, Priority: priority})
+	if status.Code(err) == codes.NotFound && tag != "" {
+		_, err = client.RandomByTag(ctx, &heads.RandomByTagIn{Priority: priority})
+	}
+	return err
+}
+
 func (h *HeadManager) SetLedsAnimation(
 	ctx context.Context,
 	parentLogger *zap.Logger,
@@ -163,3 +267,127 @@ func (h *HeadManager) AckHeartbeat(
 
 	return nil
 }
+
+// PlayAt has the head start a sound at a wall-clock time; an empty sound picks a random one
+func (h *HeadManager) PlayAt(
+	ctx context.Context,
+	headURI string,
+	sound string,
+	priority int32,
+	at time.Time,
+) error {
+	conn, err := h.GetConn(headURI)
+	if err != nil {
+		return errors.Wrap(err, "get conn")
+	}
+	_, err = heads.NewVoicesClient(conn.Conn).PlayAt(ctx, &heads.PlayAtIn{
+		Sound:    sound,
+		At:       timestamppb.New(at),
+		Random:   sound == "",
+		Priority: priority,
+	})
+	return errors.Wrap(err, "play at")
+}
+
+// Chorus has all the heads start the sound at the same instant, lead from now. The lead
+// has to cover the time it takes the requests to reach every head.
+func (h *HeadManager) Chorus(
+	ctx context.Context,
+	logger *zap.Logger,
+	headURIs []string,
+	sound string,
+	priority int32,
+	lead time.Duration,
+) {
+	at := time.Now().Add(lead)
+	h.playAll(ctx, logger, headURIs, sound, priority, func(int) time.Time { return at })
+}
+
+// Wave plays the sound around the circle of heads, each one starting spacing after the last
+func (h *HeadManager) Wave(
+	ctx context.Context,
+	logger *zap.Logger,
+	hs []*scene.Head,
+	sound string,
+	priority int32,
+	lead time.Duration,
+	spacing time.Duration,
+) {
+	var uris []string
+	for _, head := range scene.AroundCircle(hs) {
+		uris = append(uris, head.URI())
+	}
+
+	start := time.Now().Add(lead)
+	h.playAll(ctx, logger, uris, sound, priority, func(i int) time.Time {
+		return start.Add(time.Duration(i) * spacing)
+	})
+}
+
+func (h *HeadManager) playAll(
+	ctx context.Context,
+	logger *zap.Logger,
+	headURIs []string,
+	sound string,
+	priority int32,
+	at func(i int) time.Time,
+) {
+	var wg sync.WaitGroup
+	for i, uri := range headURIs {
+		wg.Add(1)
+		go func(i int, uri string) {
+			defer wg.Done()
+			if err := h.PlayAt(ctx, uri, sound, priority, at(i)); err != nil {
+				logger.Error("error scheduling sound", zap.Error(err), zap.String("uri", uri))
+			}
+		}(i, uri)
+	}
+	wg.Wait()
+}
+
+// Schedule has the service at uri make the call itself at a wall-clock time. Everything
+// scheduled for the same moment runs together, however long the requests take to arrive.
+func (h *HeadManager) Schedule(
+	ctx context.Context,
+	uri string,
+	id string,
+	at time.Time,
+	method string,
+	request proto.Message,
+) (*heads.Scheduled, error) {
+	conn, err := h.GetConn(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "get conn")
+	}
+
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal")
+	}
+
+	result, err := heads.NewSchedulerClient(conn.Conn).Schedule(ctx, &heads.ScheduleIn{
+		Id:      id,
+		At:      timestamppb.New(at),
+		Method:  method,
+		Request: body,
+	})
+	return result, errors.Wrap(err, "schedule")
+}
+
+// CancelScheduled cancels a call made with Schedule, or everything still to run if id is
+// empty. It returns how many calls were cancelled.
+func (h *HeadManager) CancelScheduled(ctx context.Context, uri string, id string) (int, error) {
+	conn, err := h.GetConn(uri)
+	if err != nil {
+		return 0, errors.Wrap(err, "get conn")
+	}
+
+	out, err := heads.NewSchedulerClient(conn.Conn).Cancel(ctx, &heads.CancelIn{
+		Id:  id,
+		All: id == "",
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "cancel")
+	}
+	return int(out.Cancelled), nil
+}
@@ -0,0 +1,155 @@
+package liveness
+
+import (
+	"github.com/minor-industries/platform/common/broker"
+	"github.com/minor-industries/theheads/boss/liveness/schema"
+	"go.uber.org/zap"
+	"sort"
+	"sync"
+	"time"
+)
+
+type key struct {
+	component, instance string
+}
+
+type Tracker struct {
+	logger      *zap.Logger
+	broker      *broker.Broker
+	interval    time.Duration
+	missedBeats int
+
+	lock     sync.Mutex
+	statuses map[key]*schema.Status
+	since    map[key]time.Time // the last beat, or when the component was expected
+}
+
+func NewTracker(
+	logger *zap.Logger,
+	broker *broker.Broker,
+	interval time.Duration,
+	missedBeats int,
+) *Tracker {
+	if interval <= 0 || missedBeats <= 0 {
+		panic("liveness interval and missed beats must be positive")
+	}
+
+	return &Tracker{
+		logger:      logger,
+		broker:      broker,
+		interval:    interval,
+		missedBeats: missedBeats,
+		statuses:    map[key]*schema.Status{},
+		since:       map[key]time.Time{},
+	}
+}
+
+// Expect tracks a component before it has sent anything, so one that never comes up
+// dies like any other
+func (t *Tracker) Expect(component, instance string, now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	k := key{component, instance}
+	if _, ok := t.statuses[k]; ok {
+		return
+	}
+	t.statuses[k] = &schema.Status{Component: component, Instance: instance, Alive: true}
+	t.since[k] = now
+}
+
+// Beat records an acked heartbeat
+func (t *Tracker) Beat(component, instance string, rtt time.Duration, now time.Time) {
+	t.lock.Lock()
+	k := key{component, instance}
+	st, ok := t.statuses[k]
+	if !ok {
+		st = &schema.Status{Component: component, Instance: instance, Alive: true}
+		t.statuses[k] = st
+	}
+	revived := !st.Alive
+
+	st.Alive = true
+	st.LastBeat = now
+	st.RTT = rtt
+	st.Missed = 0
+	t.since[k] = now
+	changed := *st
+	t.lock.Unlock()
+
+	gAlive.WithLabelValues(component, instance).Set(1)
+	gRTT.WithLabelValues(component, instance).Set(rtt.Seconds())
+
+	if revived {
+		t.changed(changed)
+	}
+}
+
+// Check counts missed beats, and marks components dead once they've missed too many
+func (t *Tracker) Check(now time.Time) {
+	var died []schema.Status
+
+	t.lock.Lock()
+	for k, st := range t.statuses {
+		st.Missed = int(now.Sub(t.since[k]) / t.interval)
+		if st.Alive && st.Missed >= t.missedBeats {
+			st.Alive = false
+			died = append(died, *st)
+			gAlive.WithLabelValues(st.Component, st.Instance).Set(0)
+		}
+	}
+	t.lock.Unlock()
+
+	for _, st := range died {
+		t.changed(st)
+	}
+
+	t.broker.Publish(&schema.Report{Statuses: t.Statuses()})
+}
+
+func (t *Tracker) changed(st schema.Status) {
+	t.logger.Info(
+		"liveness changed",
+		zap.String("component", st.Component),
+		zap.String("instance", st.Instance),
+		zap.Bool("alive", st.Alive),
+		zap.Time("last_beat", st.LastBeat),
+	)
+	t.broker.Publish(&schema.Changed{Status: st})
+}
+
+func (t *Tracker) Run() {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		t.Check(now)
+	}
+}
+
+// Alive is true for components the tracker doesn't know about, so nothing is skipped
+// for lack of heartbeats
+func (t *Tracker) Alive(component, instance string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	st, ok := t.statuses[key{component, instance}]
+	return !ok || st.Alive
+}
+
+func (t *Tracker) Statuses() []schema.Status {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	result := make([]schema.Status, 0, len(t.statuses))
+	for _, st := range t.statuses {
+		result = append(result, *st)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Component != result[j].Component {
+			return result[i].Component < result[j].Component
+		}
+		return result[i].Instance < result[j].Instance
+	})
+	return result
+}
@@ -0,0 +1,59 @@
+package liveness
+
+import (
+	"github.com/minor-industries/platform/common/broker"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func newTestTracker() *Tracker {
+	b := broker.NewBroker()
+	go b.Start()
+	return NewTracker(zap.NewNop(), b, time.Second, 3)
+}
+
+func TestTracker(t *testing.T) {
+	tr := newTestTracker()
+	start := time.Date(2022, 8, 1, 20, 0, 0, 0, time.UTC)
+
+	tr.Expect("head", "01", start)
+	tr.Expect("head", "02", start)
+	assert.True(t, tr.Alive("head", "01"))
+	assert.True(t, tr.Alive("head", "99"), "unknown components aren't skipped")
+
+	for i := 1; i <= 5; i++ {
+		now := start.Add(time.Duration(i) * time.Second)
+		tr.Beat("head", "01", 12*time.Millisecond, now)
+		tr.Check(now)
+	}
+
+	assert.True(t, tr.Alive("head", "01"))
+	assert.False(t, tr.Alive("head", "02"), "never beat")
+
+	statuses := tr.Statuses()
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, "01", statuses[0].Instance)
+	assert.Equal(t, 12*time.Millisecond, statuses[0].RTT)
+	assert.Equal(t, 5, statuses[1].Missed)
+
+	// 01 goes quiet, then comes back
+	tr.Check(start.Add(7 * time.Second))
+	assert.True(t, tr.Alive("head", "01"), "only missed one beat")
+	tr.Check(start.Add(8 * time.Second))
+	assert.False(t, tr.Alive("head", "01"))
+
+	tr.Beat("head", "01", 20*time.Millisecond, start.Add(9*time.Second))
+	assert.True(t, tr.Alive("head", "01"))
+}
+
+func TestTracker_ExpectKeepsBeats(t *testing.T) {
+	tr := newTestTracker()
+	start := time.Date(2022, 8, 1, 20, 0, 0, 0, time.UTC)
+
+	tr.Beat("head", "01", time.Millisecond, start)
+	tr.Expect("head", "01", start.Add(10*time.Second))
+	tr.Check(start.Add(10 * time.Second))
+	assert.False(t, tr.Alive("head", "01"))
+}
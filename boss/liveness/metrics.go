@@ -0,0 +1,21 @@
+package liveness
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	gAlive = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "heads",
+		Subsystem: "boss",
+		Name:      "component_alive",
+	}, []string{"component", "instance"})
+
+	gRTT = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "heads",
+		Subsystem: "boss",
+		Name:      "heartbeat_rtt_seconds",
+	}, []string{"component", "instance"})
+)
+
+func init() {
+	prometheus.MustRegister(gAlive, gRTT)
+}
@@ -0,0 +1,31 @@
+package schema
+
+import "time"
+
+// Status is what boss knows of a component from its heartbeats
+type Status struct {
+	Component string
+	Instance  string
+	Alive     bool
+	LastBeat  time.Time     // zero until the first beat
+	RTT       time.Duration // from boss to the component and back, acking the last beat
+	Missed    int           // beats missed since the last one
+}
+
+// Changed is published when a component dies or comes back
+type Changed struct {
+	Status
+}
+
+func (*Changed) Name() string {
+	return "liveness-changed"
+}
+
+// Report has the status of every component, and is published on every check
+type Report struct {
+	Statuses []Status
+}
+
+func (*Report) Name() string {
+	return "liveness-report"
+}
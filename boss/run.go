@@ -9,34 +9,44 @@ import (
 	"github.com/minor-industries/theheads/boss/cfg"
 	"github.com/minor-industries/theheads/boss/day"
 	"github.com/minor-industries/theheads/boss/day/camera_feed"
+	"github.com/minor-industries/theheads/boss/day/composite"
+	"github.com/minor-industries/theheads/boss/day/solar_position"
 	"github.com/minor-industries/theheads/boss/day/time_based"
 	"github.com/minor-industries/theheads/boss/dj"
+	"github.com/minor-industries/theheads/boss/floodlights"
 	"github.com/minor-industries/theheads/boss/grid"
 	"github.com/minor-industries/theheads/boss/head_manager"
+	"github.com/minor-industries/theheads/boss/liveness"
 	"github.com/minor-industries/theheads/boss/scene"
 	"github.com/minor-industries/theheads/boss/scenes/basic"
+	"github.com/minor-industries/theheads/boss/scenes/dance"
 	"github.com/minor-industries/theheads/boss/scenes/find_zeros"
 	"github.com/minor-industries/theheads/boss/scenes/follow_convo"
 	"github.com/minor-industries/theheads/boss/scenes/freakout"
 	"github.com/minor-industries/theheads/boss/server"
 	"github.com/minor-industries/theheads/boss/services"
+	"github.com/minor-industries/theheads/boss/volume"
 	"github.com/minor-industries/theheads/boss/watchdog"
+	"github.com/minor-industries/theheads/config"
 	"go.uber.org/zap"
 	"io/fs"
 	"os"
 	"strconv"
+	"time"
 )
 
 //go:embed frontend/fe
 var fe embed.FS
 
-func Run(env *cfg.Cfg, discovery discovery.Discovery) {
+func Run(live *config.Live[cfg.Cfg], discovery discovery.Discovery) {
+	env := live.Get()
 	if env.CheckInTime == 0 {
 		panic("boss check-in time can't be zero")
 	}
 
 	boss := &app.Boss{
-		Env: env,
+		Env:  env,
+		Live: live,
 	}
 
 	util.SetRandSeed()
@@ -48,7 +58,7 @@ func Run(env *cfg.Cfg, discovery discovery.Discovery) {
 		panic(err)
 	}
 
-	go watchdog.Watch(boss.Logger)
+	go watchdog.Watch(boss.Logger, os.ExpandEnv(env.WatchdogDumpPath))
 
 	boss.Broker = broker.NewBroker()
 	go boss.Broker.Start()
@@ -62,6 +72,12 @@ func Run(env *cfg.Cfg, discovery discovery.Discovery) {
 		panic(err)
 	}
 
+	boss.Liveness = liveness.NewTracker(boss.Logger, boss.Broker, env.HeartbeatInterval, env.MissedHeartbeats)
+	for _, head := range boss.Scene.Heads {
+		boss.Liveness.Expect("head", head.Name, time.Now())
+	}
+	go boss.Liveness.Run()
+
 	boss.Grid = grid.NewGrid(
 		boss.Logger,
 		env.SpawnPeriod,
@@ -98,25 +114,7 @@ func Run(env *cfg.Cfg, discovery discovery.Discovery) {
 		panic(err)
 	}
 
-	{
-		controller, args := env.DayDetector[0], env.DayDetector[1:]
-		var detector day.Detector
-		switch controller {
-		case "time-based":
-			detector = time_based.NewDetector(args...)
-		case "camera-feed":
-			threshold, err := strconv.ParseFloat(args[0], 64)
-			if err != nil {
-				panic(err)
-			}
-			d := camera_feed.NewDetector(boss.Broker, threshold)
-			go d.Run()
-			detector = d
-		default:
-			panic("unknown day detector")
-		}
-		boss.DayDetector = detector
-	}
+	boss.DayDetector = dayDetector(boss.Broker, env.DayDetector)
 
 	go boss.ProcessEvents()
 
@@ -134,9 +132,68 @@ func Run(env *cfg.Cfg, discovery discovery.Discovery) {
 		"follow_convo":     {followConvo.Run, 5 * 60},
 		"idle":             {basic.Idle, 60},
 		"freakout":         {freakout.Freakout, 60},
+		"dance":            {dance.Dance, 60},
 	}
 
 	boss.HeadManager = head_manager.NewHeadManager(boss.Logger, boss.Env, boss.Directory)
 
+	go floodlights.NewController(
+		boss.Logger,
+		env.FloodlightController,
+		boss.Scene,
+		boss.Grid,
+		boss.DayDetector,
+		boss.HeadManager,
+	).Run()
+
+	go volume.NewController(
+		boss.Logger,
+		boss.Live,
+		boss.Scene,
+		boss.HeadManager,
+	).Run()
+
 	dj.NewDJ(boss, allScenes).RunScenes()
 }
+
+func dayDetector(b *broker.Broker, config []string) day.Detector {
+	controller, args := config[0], config[1:]
+
+	cameraFeed := func(arg string) *camera_feed.Detector {
+		threshold, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			panic(err)
+		}
+		d := camera_feed.NewDetector(b, threshold)
+		go d.Run()
+		return d
+	}
+
+	switch controller {
+	case "time-based":
+		return time_based.NewDetector(args...)
+	case "camera-feed":
+		return cameraFeed(args[0])
+	case "solar-position":
+		// lat;lon;start;end e.g. solar-position;40.78;-119.21;civil-dawn;civil-dusk+30m
+		return solar_position.NewDetector(args...)
+	case "solar-camera":
+		// solar-position args followed by the camera-feed threshold and the hold time
+		if len(args) != 6 {
+			panic("solar-camera day detector needs lat, lon, start, end, threshold and hold")
+		}
+		hold, err := time.ParseDuration(args[5])
+		if err != nil {
+			panic(err)
+		}
+		d := composite.NewDetector(
+			hold,
+			solar_position.NewDetector(args[:4]...),
+			cameraFeed(args[4]),
+		)
+		go d.Run()
+		return d
+	default:
+		panic("unknown day detector")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"math/rand"
 	"os"
 	"path"
+	"sort"
 	"strings"
 	"time"
 )
@@ -31,6 +32,8 @@ type Scene struct {
 	// TODO: don't hang these config values off of here
 	CameraSensitivity float64
 
+	Floodlight *FloodlightPolicy
+
 	HeadMap map[string]*Head `toml:"-"`
 	Heads   []*Head
 
@@ -68,6 +71,8 @@ type Head struct {
 	Rot     float64
 	Virtual bool
 
+	VolumeOffset float64 // dB, to even out speakers which are louder or quieter than the rest
+
 	Path  []string  `toml:"-"`
 	M     geom2.Mat `toml:"-"`
 	MInv  geom2.Mat `toml:"-"`
@@ -120,6 +125,31 @@ func ShuffledHeads(heads map[string]*Head) (result []*Head) {
 	return
 }
 
+// AroundCircle orders heads by their angle around the middle of the group
+func AroundCircle(heads []*Head) []*Head {
+	if len(heads) == 0 {
+		return nil
+	}
+
+	var cx, cy float64
+	for _, h := range heads {
+		p := h.GlobalPos()
+		cx += p.X() / float64(len(heads))
+		cy += p.Y() / float64(len(heads))
+	}
+
+	angle := func(h *Head) float64 {
+		p := h.GlobalPos()
+		return math.Atan2(p.Y()-cy, p.X()-cx)
+	}
+
+	result := append([]*Head(nil), heads...)
+	sort.Slice(result, func(i, j int) bool {
+		return angle(result[i]) < angle(result[j])
+	})
+	return result
+}
+
 func (s *Scene) HeadURIs() []string {
 	var result []string
 	for _, head := range s.Heads {
@@ -169,6 +199,8 @@ type Stand struct {
 
 	Disabled bool
 
+	Floodlight *FloodlightPolicy
+
 	M geom2.Mat `toml:"-"`
 
 	Cameras []*Camera `toml:"-"`
@@ -177,6 +209,44 @@ type Stand struct {
 	CameraMap map[string]*Camera `toml:"-"`
 	HeadMap   map[string]*Head   `toml:"-"`
 }
+
+// FloodlightPolicy decides when a stand's floodlights are on. It can be set for the
+// whole scene and overridden per stand; unset fields fall back to the scene policy. The
+// numbers are pointers so a stand can set them back to zero.
+type FloodlightPolicy struct {
+	Mode string // on, off, night, presence (night and someone near the stand) or random
+
+	MinOnSeconds      *float64
+	MaxDutyCycle      *float64 // fraction of the duty window the light may be on, 0 for no limit
+	DutyWindowSeconds *float64
+	PresenceRadius    *float64
+}
+
+// Merge returns a copy of p with unset fields taken from fallback
+func (p *FloodlightPolicy) Merge(fallback FloodlightPolicy) FloodlightPolicy {
+	if p == nil {
+		return fallback
+	}
+
+	result := *p
+	if result.Mode == "" {
+		result.Mode = fallback.Mode
+	}
+	if result.MinOnSeconds == nil {
+		result.MinOnSeconds = fallback.MinOnSeconds
+	}
+	if result.MaxDutyCycle == nil {
+		result.MaxDutyCycle = fallback.MaxDutyCycle
+	}
+	if result.DutyWindowSeconds == nil {
+		result.DutyWindowSeconds = fallback.DutyWindowSeconds
+	}
+	if result.PresenceRadius == nil {
+		result.PresenceRadius = fallback.PresenceRadius
+	}
+	return result
+}
+
 type Translate struct {
 	X int
 	Y int
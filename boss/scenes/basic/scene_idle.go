@@ -3,12 +3,24 @@ package basic
 import (
 	"github.com/minor-industries/theheads/boss/dj"
 	"github.com/minor-industries/theheads/boss/watchdog"
+	"go.uber.org/zap"
 	"time"
 )
 
 func Idle(sp *dj.SceneParams) {
+	// look around slowly instead of sitting frozen
+	for _, head := range sp.DJ.LiveHeads() {
+		_, err := sp.DJ.HeadManager.SetActorParams(sp.Ctx, head.URI(), "LookAround", map[string]float64{
+			"min_pause": 3,
+			"max_pause": 10,
+		})
+		if err != nil {
+			sp.Logger.Error("error setting actor", zap.String("uri", head.URI()), zap.Error(err))
+		}
+	}
+
 	for {
-		watchdog.Feed()
+		watchdog.Feed("dj", "idle")
 
 		select {
 		case <-time.After(5 * time.Second):
@@ -0,0 +1,83 @@
+package dance
+
+import (
+	geom2 "github.com/minor-industries/platform/common/geom"
+	"github.com/minor-industries/protobuf/gen/go/heads"
+	"github.com/minor-industries/theheads/boss/dj"
+	"github.com/minor-industries/theheads/boss/scene"
+	"github.com/minor-industries/theheads/boss/scenes"
+	"github.com/minor-industries/theheads/boss/watchdog"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"sync"
+	"time"
+)
+
+const (
+	lead    = 3 * time.Second // covers getting the timelines to every head
+	spacing = 400 * time.Millisecond
+	settle  = 2 * time.Second
+)
+
+// Dance has the heads face each other, then spin around one after another like a wave
+// going around the circle, and finally settle back facing the middle together
+func Dance(sp *dj.SceneParams) {
+	defer func() {
+		sp.Logger.Info("done dancing")
+		watchdog.Feed("dj", "dance finished")
+		sp.Done.Close()
+	}()
+
+	scenes.SceneSetup(sp, "rainbow")
+
+	var hs []*scene.Head
+	for _, head := range sp.DJ.LiveHeads() {
+		hs = append(hs, head)
+	}
+	hs = scene.AroundCircle(hs)
+	if len(hs) == 0 {
+		return
+	}
+
+	var cx, cy float64
+	for _, head := range hs {
+		p := head.GlobalPos()
+		cx += p.X() / float64(len(hs))
+		cy += p.Y() / float64(len(hs))
+	}
+	middle := geom2.NewVec(cx, cy)
+
+	end := time.Duration(len(hs))*spacing + 4*time.Second + settle
+	start := time.Now().Add(lead)
+
+	var wg sync.WaitGroup
+	for i, head := range hs {
+		wg.Add(1)
+		go func(i int, head *scene.Head) {
+			defer wg.Done()
+			keyframes := spin(head.PointTo(middle), time.Duration(i)*spacing, end)
+			if _, err := sp.DJ.HeadManager.RunTimeline(sp.Ctx, head.URI(), "dance", start, keyframes); err != nil {
+				sp.Logger.Error("error running timeline", zap.Error(err), zap.String("uri", head.URI()))
+			}
+		}(i, head)
+	}
+	wg.Wait()
+
+	sp.DJ.Sleep(sp.Done, time.Until(start.Add(end)))
+}
+
+// spin faces theta, waits for the wave to come around, then turns a full circle and
+// breathes until the end
+func spin(theta float64, offset, end time.Duration) []*heads.Keyframe {
+	at := func(d time.Duration) *durationpb.Duration {
+		return durationpb.New(d)
+	}
+
+	return []*heads.Keyframe{
+		{At: at(0), Theta: theta},
+		{At: at(offset + settle/2), Theta: theta},
+		{At: at(offset + settle/2 + 2*time.Second), Theta: theta + 180, Easing: "ease-in"},
+		{At: at(offset + settle/2 + 4*time.Second), Theta: theta + 360, Easing: "ease-out", Actor: "Breathe"},
+		{At: at(end), Theta: theta + 360},
+	}
+}
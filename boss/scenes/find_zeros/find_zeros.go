@@ -18,11 +18,9 @@ func FindZeros(sp *dj.SceneParams) {
 
 	scenes.SceneSetup(sp, "rainbow")
 
-	go setupFloodLights(sp)
-	go setVolume(sp)
 	go findHeadZeros(sp)
 
 	<-sp.Done.Chan()
 	sp.Logger.Info("Exiting FindZeros")
-	watchdog.Feed()
+	watchdog.Feed("dj", "find_zeros finished")
 }
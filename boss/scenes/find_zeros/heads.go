@@ -1,6 +1,7 @@
 package find_zeros
 
 import (
+	"context"
 	"github.com/minor-industries/protobuf/gen/go/heads"
 	"github.com/minor-industries/theheads/boss/dj"
 	"github.com/minor-industries/theheads/boss/head_manager"
@@ -10,6 +11,9 @@ import (
 	"time"
 )
 
+// zeroes older than this are checked again anyway
+const rezeroAfter = 24 * time.Hour
+
 func setupHead(
 	sp *dj.SceneParams,
 	ws *sync.WaitGroup,
@@ -23,15 +27,30 @@ func setupHead(
 		return
 	}
 
+	if zeroed(sp, conn) {
+		sp.Logger.Info("head already zeroed, skipping")
+		return
+	}
+
 	findHeadZero(sp, conn)
 }
 
+// zeroed says the head still knows where zero is, e.g. across a restart, so there's no
+// need to search for it again
+func zeroed(sp *dj.SceneParams, conn *head_manager.Connection) bool {
+	status, err := heads.NewHeadClient(conn.Conn).Status(sp.Ctx, &heads.Empty{})
+	if err != nil {
+		return false
+	}
+	return status.Zeroed &&
+		!status.PositionUncertain &&
+		time.Since(status.ZeroedAt.AsTime()) < rezeroAfter
+}
+
 func findHeadZero(
 	sp *dj.SceneParams,
 	conn *head_manager.Connection,
 ) {
-	ticker := time.NewTicker(1 * time.Second)
-
 	client := heads.NewHeadClient(conn.Conn)
 
 	_, err := client.FindZero(sp.Ctx, &heads.Empty{})
@@ -40,26 +59,40 @@ func findHeadZero(
 		return
 	}
 
-	for {
+	ctx, cancel := context.WithCancel(sp.Ctx)
+	defer cancel()
+	go func() {
 		select {
-		case <-ticker.C:
-			status, err := client.Status(sp.Ctx, &heads.Empty{})
-			if err != nil {
-				sp.Logger.Error("error polling head status", zap.Error(err))
-				continue
-			}
+		case <-sp.Done.Chan():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 
-			sp.Logger.Debug("polled head status", zap.String("controller", status.Controller))
+	// the search is already under way, so the first state is about it too
+	watch, err := client.WatchState(ctx, &heads.WatchStateIn{})
+	if err != nil {
+		sp.Logger.Error("error watching head state", zap.Error(err))
+		return
+	}
 
-			if status.Controller != "" {
-				switch status.Controller {
-				case "ZeroDetector", "magnetometer zero detector":
-					continue // zero detection not complete
-				}
-				return
+	for {
+		state, err := watch.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				sp.Logger.Warn("FindZeros exited without finding all zeros")
+			} else {
+				sp.Logger.Error("error watching head state", zap.Error(err))
 			}
-		case <-sp.Done.Chan():
-			sp.Logger.Warn("FindZeros exited without finding all zeros")
+			return
+		}
+
+		switch state.ZeroStatus {
+		case heads.ZeroStatus_ZERO_FOUND:
+			sp.Logger.Info("found zero")
+			return
+		case heads.ZeroStatus_ZERO_FAILED:
+			sp.Logger.Error("error finding zero", zap.String("reason", state.ZeroError))
 			return
 		}
 	}
@@ -68,7 +101,7 @@ func findHeadZero(
 func findHeadZeros(sp *dj.SceneParams) {
 	ws := &sync.WaitGroup{}
 
-	for _, h := range sp.DJ.Scene.HeadMap {
+	for _, h := range sp.DJ.LiveHeads() {
 		ws.Add(1)
 		newSp := sp.WithLogger(sp.Logger.With(zap.String("head", h.URI())))
 		go setupHead(newSp, ws, h)
@@ -3,6 +3,7 @@ package scenes
 import (
 	"github.com/minor-industries/platform/common/geom"
 	"github.com/minor-industries/theheads/boss/dj"
+	"github.com/minor-industries/theheads/boss/head_manager"
 	"github.com/minor-industries/theheads/boss/scene"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -12,9 +13,12 @@ import (
 
 const (
 	trackingPeriod = 40 * time.Millisecond
+	idleAfter      = 10 * time.Second
+	idleActor      = "LookAround"
 )
 
-type Tracker func(sp *dj.SceneParams, head *scene.Head) error
+// Tracker points the head at something, and reports false when there's nothing to follow
+type Tracker func(sp *dj.SceneParams, head *scene.Head) (bool, error)
 
 func Track(
 	sp *dj.SceneParams,
@@ -34,13 +38,34 @@ func Track(
 	ticker := time.NewTicker(trackingPeriod)
 	defer ticker.Stop()
 
+	// glance around rather than freezing while nobody's there
+	lastSeen := time.Now()
+	idling := false
+	setActor := func(actor string) {
+		if _, err := sp.DJ.HeadManager.SetActor(sp.Ctx, head.URI(), actor); err != nil {
+			sp.Logger.Error("error setting actor", zap.Error(err))
+		}
+	}
+
 	for {
 		select {
 		case <-ticker.C:
-			err := tracker(sp, head)
+			found, err := tracker(sp, head)
 			if err != nil {
 				sp.Logger.Error("error following head", zap.Error(err))
 			}
+
+			switch {
+			case found:
+				lastSeen = time.Now()
+				if idling {
+					idling = false
+					setActor(initialHeadActor)
+				}
+			case !idling && time.Since(lastSeen) > idleAfter:
+				idling = true
+				setActor(idleActor)
+			}
 		case <-sp.Done.Chan():
 			sp.Logger.Debug("Finishing Track")
 			return
@@ -48,47 +73,48 @@ func Track(
 	}
 }
 
-func TrackClosestFocalPoint(
-	sp *dj.SceneParams,
-	head *scene.Head,
-) error {
-	p := head.GlobalPos()
+func TrackClosestFocalPoint(motion head_manager.Motion) Tracker {
+	return func(sp *dj.SceneParams, head *scene.Head) (bool, error) {
+		p := head.GlobalPos()
 
-	selected, _ := sp.DJ.Grid.ClosestFocalPointTo(p)
-	if selected == nil {
-		return nil
-	}
+		selected, _ := sp.DJ.Grid.ClosestFocalPointTo(p)
+		if selected == nil {
+			return false, nil
+		}
 
-	theta := head.PointTo(geom.NewVec(selected.Pos.X, selected.Pos.Y))
+		theta := head.PointTo(geom.NewVec(selected.Pos.X, selected.Pos.Y))
 
-	if _, err := sp.DJ.HeadManager.SetTarget(sp.Ctx, head.URI(), theta); err != nil {
-		return errors.Wrap(err, "set target")
-	}
+		if _, err := sp.DJ.HeadManager.SetTarget(sp.Ctx, head.URI(), theta, motion); err != nil {
+			return true, errors.Wrap(err, "set target")
+		}
 
-	return nil
+		return true, nil
+	}
 }
 
-func TrackEvadeFocalPoint(
-	sp *dj.SceneParams,
-	head *scene.Head,
-) error {
-	p := head.GlobalPos()
+func TrackEvadeFocalPoint(motion head_manager.Motion) Tracker {
+	return func(sp *dj.SceneParams, head *scene.Head) (bool, error) {
+		p := head.GlobalPos()
 
-	selected, distance := sp.DJ.Grid.ClosestFocalPointTo(p)
-	if selected == nil || distance < 0.01 {
-		return nil
-	}
+		selected, distance := sp.DJ.Grid.ClosestFocalPointTo(p)
+		if selected == nil {
+			return false, nil
+		}
+		if distance < 0.01 {
+			return true, nil
+		}
 
-	var theta float64
-	if head.Fearful() {
-		theta = head.PointAwayFrom(geom.NewVec(selected.Pos.X, selected.Pos.Y))
-	} else {
-		theta = head.PointTo(geom.NewVec(selected.Pos.X, selected.Pos.Y))
-	}
+		var theta float64
+		if head.Fearful() {
+			theta = head.PointAwayFrom(geom.NewVec(selected.Pos.X, selected.Pos.Y))
+		} else {
+			theta = head.PointTo(geom.NewVec(selected.Pos.X, selected.Pos.Y))
+		}
 
-	if _, err := sp.DJ.HeadManager.SetTarget(sp.Ctx, head.URI(), theta); err != nil {
-		return errors.Wrap(err, "set target")
-	}
+		if _, err := sp.DJ.HeadManager.SetTarget(sp.Ctx, head.URI(), theta, motion); err != nil {
+			return true, errors.Wrap(err, "set target")
+		}
 
-	return nil
+		return true, nil
+	}
 }
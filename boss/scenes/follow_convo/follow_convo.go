@@ -4,6 +4,7 @@ import (
 	"github.com/minor-industries/platform/common/geom"
 	"github.com/minor-industries/platform/schema"
 	"github.com/minor-industries/theheads/boss/dj"
+	"github.com/minor-industries/theheads/boss/head_manager"
 	"github.com/minor-industries/theheads/boss/rate_limiter"
 	"github.com/minor-industries/theheads/boss/scene"
 	"github.com/minor-industries/theheads/boss/scenes"
@@ -32,12 +33,12 @@ func (f *FollowConvo) Run(sp *dj.SceneParams) {
 	f.setup(sp.DJ)
 
 	//go f.interruptScene(sp, randomlyInterrupt())
-	go f.interruptScene(sp, fearfulInterrupt(sp, sp.DJ.Boss.Env.FearfulCount, 90*time.Second))
+	go f.interruptScene(sp, fearfulInterrupt(sp, sp.DJ.Boss.Live.Get().FearfulCount, 90*time.Second))
 
 	scenes.SceneSetup(sp, "rainbow")
 
-	for _, head := range sp.DJ.Scene.HeadMap {
-		go scenes.Track(sp, head, "Seeker", scenes.TrackEvadeFocalPoint)
+	for _, head := range sp.DJ.LiveHeads() {
+		go scenes.Track(sp, head, "Seeker", scenes.TrackEvadeFocalPoint(head_manager.MotionCreep))
 		go scenes.EnableFaceDetection(sp, head)
 	}
 
@@ -67,7 +68,7 @@ func (f *FollowConvo) Run(sp *dj.SceneParams) {
 		delay := (300 + time.Duration(rand.Intn(400))) * time.Millisecond
 		sp.DJ.Sleep(sp.Done, delay)
 
-		watchdog.Feed()
+		watchdog.Feed("dj", "follow_convo")
 	}
 }
 
@@ -84,7 +85,7 @@ func (f *FollowConvo) setup(dj *dj.DJ) {
 func (f *FollowConvo) selectHead(dj *dj.DJ) *scene.Head {
 	var pairs []FpHeadPair
 	// find the closest (focal point, head) pairs
-	for _, h := range dj.Scene.HeadMap {
+	for _, h := range dj.LiveHeads() {
 		if h.Fearful() {
 			continue // fearful heads don't normally speak
 		}
@@ -109,8 +110,11 @@ func (f *FollowConvo) selectHead(dj *dj.DJ) *scene.Head {
 	var choices []FpHeadPair
 
 	if len(pairs) == 0 {
-		i := rand.Intn(len(dj.Scene.Heads))
-		return dj.Scene.Heads[i]
+		heads := dj.LiveHeads()
+		if len(heads) == 0 {
+			heads = dj.Scene.Heads // nothing can be heard anyway, but keep the text moving
+		}
+		return heads[rand.Intn(len(heads))]
 	}
 
 	// Choose a random head to speak with some bias
@@ -2,15 +2,25 @@ package freakout
 
 import (
 	"context"
+	"github.com/minor-industries/protobuf/gen/go/heads"
 	"github.com/minor-industries/theheads/boss/dj"
+	"github.com/minor-industries/theheads/boss/head_manager"
 	"github.com/minor-industries/theheads/boss/scene"
 	"github.com/minor-industries/theheads/boss/scenes"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"math/rand"
 	"sync"
 	"time"
 )
 
+const (
+	openingLead = 500 * time.Millisecond
+	waveSpacing = 300 * time.Millisecond
+	yellFor     = 30 * time.Second
+)
+
 func Freakout(sp *dj.SceneParams) {
 	defer func() {
 		sp.Logger.Info("done freaking out")
@@ -19,20 +29,78 @@ func Freakout(sp *dj.SceneParams) {
 
 	scenes.SceneSetup(sp, "highred")
 
-	for _, head := range sp.DJ.Scene.HeadMap {
-		go scenes.Track(sp, head, "Jitter", scenes.TrackClosestFocalPoint)
+	for _, head := range sp.DJ.LiveHeads() {
+		go scenes.Track(sp, head, "Jitter", scenes.TrackClosestFocalPoint(head_manager.MotionSnap))
 	}
 
-	newCtx, cancel := context.WithTimeout(sp.Ctx, 30*time.Second)
+	calm := calmDown(sp, time.Now().Add(yellFor))
+
+	newCtx, cancel := context.WithTimeout(sp.Ctx, yellFor)
 	defer cancel()
-	sp = sp.WithContext(newCtx)
-	yell(sp)
+	yelling := sp.WithContext(newCtx)
+	opening(yelling)
+	yell(yelling)
+
+	if sp.Ctx.Err() != nil {
+		// cut short, so don't switch actors under whatever comes next
+		cancelCalmDown(sp, calm)
+		return
+	}
 	sp.DJ.Sleep(sp.Done, 10*time.Second)
 }
 
+// calmDown has every head go back to seeking at the same moment once the yelling is over.
+// It returns the scheduled ids by head uri.
+func calmDown(sp *dj.SceneParams, at time.Time) map[string]string {
+	ids := map[string]string{}
+	for _, head := range sp.DJ.LiveHeads() {
+		scheduled, err := sp.DJ.HeadManager.Schedule(
+			sp.Ctx,
+			head.URI(),
+			"",
+			at,
+			head_manager.MethodSetActor,
+			&heads.SetActorIn{Actor: "Seeker"},
+		)
+		if err != nil {
+			sp.Logger.Error("error scheduling actor", zap.Error(err), zap.String("uri", head.URI()))
+			continue
+		}
+		ids[head.URI()] = scheduled.Id
+	}
+	return ids
+}
+
+func cancelCalmDown(sp *dj.SceneParams, ids map[string]string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	for uri, id := range ids {
+		if _, err := sp.DJ.HeadManager.CancelScheduled(ctx, uri, id); err != nil {
+			sp.Logger.Error("error cancelling actor", zap.Error(err), zap.String("uri", uri))
+		}
+	}
+}
+
+// opening has all the heads scream together, either at once or as a wave around the circle
+func opening(sp *dj.SceneParams) {
+	var hs []*scene.Head
+	var uris []string
+	for _, head := range sp.DJ.LiveHeads() {
+		hs = append(hs, head)
+		uris = append(uris, head.URI())
+	}
+
+	if rand.Float64() < 0.5 {
+		sp.DJ.HeadManager.Chorus(sp.Ctx, sp.Logger, uris, "", head_manager.PriorityScream, openingLead)
+	} else {
+		sp.DJ.HeadManager.Wave(sp.Ctx, sp.Logger, hs, "", head_manager.PriorityScream, openingLead, waveSpacing)
+	}
+}
+
 func yell(sp *dj.SceneParams) {
 	var wg sync.WaitGroup
-	for _, head := range sp.DJ.Scene.HeadMap {
+	for _, head := range sp.DJ.LiveHeads() {
 		wg.Add(1)
 		go headYell(sp, &wg, head)
 	}
@@ -41,23 +109,16 @@ func yell(sp *dj.SceneParams) {
 
 func headYell(sp *dj.SceneParams, wg *sync.WaitGroup, head *scene.Head) {
 	for !isDone(sp) {
-		err := sp.DJ.HeadManager.SayRandom(sp.Ctx, head.URI())
-		if err != nil && !isDone(sp) {
-			sp.Logger.Error("error playing random voice", zap.Error(err))
+		err := sp.DJ.HeadManager.SayTagged(sp.Ctx, head.URI(), "scream", head_manager.PriorityScream)
+		if status.Code(err) == codes.ResourceExhausted {
+			// still busy with the opening
+		} else if err != nil && !isDone(sp) {
+			sp.Logger.Error("error screaming", zap.Error(err))
 		}
 		delay := (300 + time.Duration(rand.Intn(400))) * time.Millisecond
 		sp.DJ.Sleep(sp.Done, delay)
 	}
 
-	func() {
-		newCtx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
-		defer cancel()
-		if _, err := sp.DJ.HeadManager.SetActor(newCtx, head.URI(), "Seeker"); err != nil {
-			sp.Logger.Error("error setting actor", zap.Error(err))
-			return
-		}
-	}()
-
 	wg.Done()
 }
 
@@ -23,6 +23,10 @@ func SetupRoutes(boss *app.Boss) (*standard_server.Server, error) {
 				c.TOML(200, boss.Scene)
 			})
 
+			r.GET("/liveness", func(c *gin.Context) {
+				c.JSON(200, boss.Liveness.Statuses())
+			})
+
 			r.GET("/", func(c *gin.Context) {
 				c.Redirect(302, "fe") // TODO: is 302 the correct code here?
 			})
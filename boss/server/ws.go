@@ -6,6 +6,7 @@ import (
 	"github.com/minor-industries/platform/common/broker"
 	"github.com/minor-industries/platform/common/wsrpc/server"
 	"github.com/minor-industries/platform/schema"
+	livenessschema "github.com/minor-industries/theheads/boss/liveness/schema"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -18,7 +19,7 @@ func manageWebsocket(conn *websocket.Conn, msgBroker *broker.Broker) {
 	for {
 		for m := range msgs {
 			switch msg := m.(type) {
-			case *schema.HeadPositioned, *schema.FocalPoints, *schema.Heartbeat:
+			case *schema.HeadPositioned, *schema.FocalPoints, *schema.Heartbeat, *livenessschema.Changed:
 				data, err := json.Marshal(msg)
 				if err != nil {
 					panic(err)
@@ -73,6 +74,12 @@ func manageWebsocket2(
 				if err != nil {
 					return errors.Wrap(err, "remote call")
 				}
+			case *livenessschema.Report:
+				empty := &struct{}{}
+				err := client.Call("Draw.Liveness", msg, &empty)
+				if err != nil {
+					return errors.Wrap(err, "remote call")
+				}
 			}
 		}
 	}
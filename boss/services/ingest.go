@@ -8,6 +8,8 @@ import (
 	"github.com/minor-industries/platform/common/discovery"
 	"github.com/minor-industries/platform/schema"
 	"github.com/minor-industries/protobuf/gen/go/heads"
+	"github.com/minor-industries/theheads/boss/watchdog"
+	"github.com/minor-industries/theheads/head/motor/messages"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -111,7 +113,15 @@ func (es *EventStreamer) streamEvents(logger *zap.Logger, addr string) {
 			}
 			defer conn.Close()
 
-			events, err := heads.NewEventsClient(conn).Stream(context.Background(), &heads.Empty{})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// heads send heartbeats and cameras send brightness, so a quiet stream is a stuck one
+			name := "stream/" + addr
+			watchdog.Register(name, time.Minute, cancel)
+			defer watchdog.Unregister(name)
+
+			events, err := heads.NewEventsClient(conn).Stream(ctx, &heads.Empty{})
 			if err != nil {
 				return err
 			}
@@ -123,6 +133,8 @@ func (es *EventStreamer) streamEvents(logger *zap.Logger, addr string) {
 					return err
 				}
 
+				watchdog.Feed(name, msg.Type)
+
 				err = es.publish(msg.Type, []byte(msg.Data))
 				if err != nil {
 					panic(err)
@@ -172,6 +184,21 @@ func (es *EventStreamer) publish(typ string, data []byte) error {
 		eventReceived.WithLabelValues(event.Type, msg.CameraName).Inc()
 		es.broker.Publish(msg)
 
+	case "position-drift":
+		msg := &messages.PositionDrift{}
+		err = json.Unmarshal(event.Data, msg)
+		if err != nil {
+			return err
+		}
+		eventReceived.WithLabelValues(event.Type, msg.HeadName).Inc()
+		es.logger.Warn(
+			"head position drifted",
+			zap.String("head", msg.HeadName),
+			zap.Int("steps", msg.Steps),
+			zap.Bool("corrected", msg.Corrected),
+		)
+		es.broker.Publish(msg)
+
 	case "heartbeat":
 		msg := &schema.Heartbeat{}
 		err = json.Unmarshal(event.Data, msg)
@@ -0,0 +1,14 @@
+package volume
+
+import (
+	"github.com/minor-industries/platform/common/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	gVoiceVolume = metrics.SimpleGauge(
+		prometheus.DefaultRegisterer,
+		"boss",
+		"voice_volume_db",
+	)
+)
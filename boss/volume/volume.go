@@ -0,0 +1,110 @@
+package volume
+
+import (
+	"context"
+	"github.com/minor-industries/theheads/boss/cfg"
+	"github.com/minor-industries/theheads/boss/head_manager"
+	"github.com/minor-industries/theheads/boss/scene"
+	"github.com/minor-industries/theheads/config"
+	"go.uber.org/zap"
+	"math"
+	"time"
+)
+
+const (
+	evaluatePeriod = 30 * time.Second
+	resendPeriod   = 5 * time.Minute // heads come back at their default volume after a restart
+)
+
+type head struct {
+	volume int32
+	sent   time.Time
+}
+
+// Controller keeps each head at the scheduled voice volume plus its calibration offset
+type Controller struct {
+	logger      *zap.Logger
+	live        *config.Live[cfg.Cfg]
+	scene       *scene.Scene
+	headManager *head_manager.HeadManager
+
+	heads map[string]*head
+}
+
+func NewController(
+	logger *zap.Logger,
+	live *config.Live[cfg.Cfg],
+	sc *scene.Scene,
+	headManager *head_manager.HeadManager,
+) *Controller {
+	return &Controller{
+		logger:      logger,
+		live:        live,
+		scene:       sc,
+		headManager: headManager,
+		heads:       map[string]*head{},
+	}
+}
+
+func (c *Controller) Run() {
+	ticker := time.NewTicker(evaluatePeriod)
+	defer ticker.Stop()
+
+	for {
+		c.evaluate(time.Now())
+		<-ticker.C
+	}
+}
+
+func (c *Controller) evaluate(now time.Time) {
+	base, err := c.live.Get().VolumeAt(now)
+	if err != nil {
+		c.logger.Error("invalid volume schedule", zap.Error(err))
+		return
+	}
+	gVoiceVolume.Set(float64(base))
+
+	for _, sh := range c.scene.HeadMap {
+		if sh.Virtual {
+			continue
+		}
+
+		uri := sh.URI()
+		h, ok := c.heads[uri]
+		if !ok {
+			h = &head{}
+			c.heads[uri] = h
+		}
+
+		volume := target(base, sh)
+		if volume == h.volume && now.Sub(h.sent) < resendPeriod {
+			continue
+		}
+
+		if c.send(uri, volume) {
+			h.volume = volume
+			h.sent = now
+		}
+	}
+}
+
+// target is the volume for a head, which is kept at or below 0dB
+func target(base int, h *scene.Head) int32 {
+	return int32(math.Min(0, math.Round(float64(base)+h.VolumeOffset)))
+}
+
+func (c *Controller) send(uri string, volume int32) bool {
+	logger := c.logger.With(zap.String("uri", uri), zap.Int32("volume", volume))
+
+	ctx, cancel := context.WithTimeout(context.Background(), evaluatePeriod)
+	defer cancel()
+
+	if err := c.headManager.SetVolume(ctx, uri, volume); err != nil {
+		// most likely not checked in yet
+		logger.Debug("error setting volume", zap.Error(err))
+		return false
+	}
+
+	logger.Info("set volume")
+	return true
+}
@@ -1,53 +1,189 @@
 package watchdog
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
 	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
+	"sync"
 	"time"
 )
 
 var (
-	lastUpdated time.Time
-	feedCh      = make(chan bool)
+	lock       sync.Mutex
+	components = map[string]*component{}
 
-	watchdogFed = promauto.NewCounter(prometheus.CounterOpts{
+	watchdogFed = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "heads_boss_watchdog_fed",
-	})
+	}, []string{"component"})
+
+	watchdogTripped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "heads_boss_watchdog_tripped",
+	}, []string{"component"})
 )
 
 const (
-	timeout       = 2 * time.Minute
 	checkInterval = 5 * time.Second
+
+	// a component which keeps tripping gets the whole process restarted instead
+	maxRestarts   = 3
+	restartWindow = 30 * time.Minute
 )
 
-func init() {
-	lastUpdated = time.Now()
+type component struct {
+	timeout time.Duration
+	restart func()
+
+	lastUpdated time.Time
+	state       string
+	restarts    []time.Time
+	paused      bool
+}
+
+// Register adds a component which has to be fed at least once per timeout. When it
+// trips, restart is called if given; otherwise the whole process exits.
+func Register(name string, timeout time.Duration, restart func()) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	c, ok := components[name]
+	if !ok {
+		c = &component{}
+		components[name] = c
+	}
+
+	c.timeout = timeout
+	c.restart = restart
+	c.lastUpdated = time.Now()
+	c.paused = false
+}
+
+// Unregister stops watching the component until it's registered again. Its restarts
+// are kept, so one that keeps reconnecting after tripping still gets the process
+// restarted.
+func Unregister(name string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if c, ok := components[name]; ok {
+		c.paused = true
+	}
+}
+
+// Feed marks the component as alive. state describes what it was last doing, and is
+// included in the diagnostics if the component later trips.
+func Feed(name string, state any) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	c, ok := components[name]
+	if !ok || c.paused {
+		return
+	}
+
+	c.lastUpdated = time.Now()
+	c.state = fmt.Sprintf("%+v", state)
+	watchdogFed.WithLabelValues(name).Inc()
 }
 
-func Feed() {
-	feedCh <- true
+type trip struct {
+	name    string
+	state   string
+	since   time.Duration
+	restart func()
 }
 
-func check(logger *zap.Logger) {
-	expiry := lastUpdated.Add(timeout)
-	if time.Now().After(expiry) {
-		logger.Error("watchdog timed out")
-		time.Sleep(time.Second)
-		os.Exit(-10)
+func check(now time.Time) []trip {
+	lock.Lock()
+	defer lock.Unlock()
+
+	var result []trip
+	for name, c := range components {
+		since := now.Sub(c.lastUpdated)
+		if c.paused || since < c.timeout {
+			continue
+		}
+
+		t := trip{name: name, state: c.state, since: since}
+
+		var recent []time.Time
+		for _, r := range c.restarts {
+			if now.Sub(r) < restartWindow {
+				recent = append(recent, r)
+			}
+		}
+		if len(recent) < maxRestarts {
+			t.restart = c.restart
+		}
+		c.restarts = append(recent, now)
+		c.lastUpdated = now
+
+		result = append(result, t)
 	}
+	return result
 }
 
-func Watch(logger *zap.Logger) {
+func dump(logger *zap.Logger, dumpPath string, t trip) {
+	buf := bytes.NewBuffer(nil)
+	_, _ = fmt.Fprintf(buf, "component: %s\nlast fed: %s ago\nlast state: %s\n\n", t.name, t.since, t.state)
+	_ = pprof.Lookup("goroutine").WriteTo(buf, 2)
+
+	if dumpPath == "" {
+		logger.Error("watchdog diagnostics", zap.String("dump", buf.String()))
+		return
+	}
+
+	filename := filepath.Join(
+		dumpPath,
+		fmt.Sprintf(
+			"watchdog-%s-%s.txt",
+			time.Now().Format("20060102-150405"),
+			strings.ReplaceAll(t.name, "/", "_"),
+		),
+	)
+
+	err := os.MkdirAll(dumpPath, 0o755)
+	if err == nil {
+		err = os.WriteFile(filename, buf.Bytes(), 0o644)
+	}
+	if err != nil {
+		logger.Error("error writing watchdog diagnostics", zap.Error(err))
+		logger.Error("watchdog diagnostics", zap.String("dump", buf.String()))
+		return
+	}
+
+	logger.Info("wrote watchdog diagnostics", zap.String("filename", filename))
+}
+
+// Watch checks all registered components. Diagnostics go to files in dumpPath, or to
+// the log if dumpPath is empty.
+func Watch(logger *zap.Logger, dumpPath string) {
 	ticker := time.NewTicker(checkInterval)
-	for {
-		select {
-		case <-feedCh:
-			watchdogFed.Inc()
-			lastUpdated = time.Now()
-		case <-ticker.C:
-			check(logger)
+	for range ticker.C {
+		for _, t := range check(time.Now()) {
+			watchdogTripped.WithLabelValues(t.name).Inc()
+
+			logger := logger.With(
+				zap.String("component", t.name),
+				zap.String("state", t.state),
+				zap.Duration("since", t.since),
+			)
+			logger.Error("watchdog timed out")
+			dump(logger, dumpPath, t)
+
+			if t.restart == nil {
+				logger.Error("restarting process")
+				time.Sleep(time.Second)
+				os.Exit(-10)
+			}
+
+			logger.Info("restarting component")
+			t.restart()
 		}
 	}
 }
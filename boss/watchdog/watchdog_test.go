@@ -0,0 +1,49 @@
+package watchdog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func tripsFor(trips []trip, name string) []trip {
+	var result []trip
+	for _, t := range trips {
+		if t.name == name {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func TestReconnectingStreamEscalates(t *testing.T) {
+	name := "stream/test-escalates"
+	restart := func() {}
+
+	now := time.Now()
+	for i := 0; i < maxRestarts+1; i++ {
+		// each reconnect registers the stream again, as ingest does
+		Register(name, time.Minute, restart)
+
+		now = now.Add(2 * time.Minute)
+		trips := tripsFor(check(now), name)
+		require.Len(t, trips, 1)
+
+		if i < maxRestarts {
+			assert.NotNil(t, trips[0].restart, "trip %d", i)
+		} else {
+			assert.Nil(t, trips[0].restart, "should restart the process")
+		}
+
+		Unregister(name)
+	}
+}
+
+func TestUnregisteredDoesNotTrip(t *testing.T) {
+	name := "stream/test-paused"
+	Register(name, time.Minute, func() {})
+	Unregister(name)
+
+	assert.Empty(t, tripsFor(check(time.Now().Add(time.Hour)), name))
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/minor-industries/theheads/camera/source/mjpeg/webcam"
 	"github.com/minor-industries/theheads/camera/source/raspivid_recorder"
 	"github.com/minor-industries/theheads/camera/util"
+	"github.com/minor-industries/theheads/config"
+	"github.com/minor-industries/theheads/schedule"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/atomic"
@@ -48,6 +50,7 @@ type cameraMetrics struct {
 type Camera struct {
 	logger    *zap.Logger
 	env       *cfg.Cfg
+	live      *config.Live[cfg.Cfg] // for the motion settings, which can be reloaded
 	registry  *prometheus.Registry
 	grabber   source.FrameGrabber
 	ff        *ffmpeg.Ffmpeg
@@ -66,16 +69,18 @@ type Camera struct {
 
 func NewCamera(
 	logger *zap.Logger,
-	env *cfg.Cfg,
+	live *config.Live[cfg.Cfg],
 	mainBroker *broker.Broker,
 	floodlight *floodlight.Floodlight,
 ) *Camera {
+	env := live.Get()
 	registry := metrics.NewRegistry()
 	wsBroker := broker.NewBroker()
 
 	c := &Camera{
 		logger:            logger,
 		env:               env,
+		live:              live,
 		broker:            mainBroker,
 		floodlight:        floodlight,
 		currentBrightness: &atomic.Float64{},
@@ -177,6 +182,9 @@ func (c *Camera) Setup() {
 			heads.RegisterEventsServer(s, h)
 			heads.RegisterPingServer(s, h)
 			heads.RegisterRecorderServer(s, h)
+			heads.RegisterSchedulerServer(s, newScheduler(
+				schedule.New(c.logger, c.registry, schedule.Loopback(c.env.Port)),
+			))
 			return nil
 		},
 		Registry: c.registry,
@@ -294,7 +302,7 @@ func (c *Camera) readFrame() {
 		c.currentBrightness.Store(brightness)
 	})
 
-	motion := c.md.Detect(c.env, c.preScaled)
+	motion := c.md.Detect(c.live.Get(), c.preScaled)
 	maxRecord := c.processMotion(motion)
 
 	if c.env.DetectFaces {
@@ -354,9 +362,10 @@ func (c *Camera) processMotion(
 ) *motion_detector.MotionRecord {
 	maxArea := 0.0
 	var maxRecord *motion_detector.MotionRecord
+	minArea := c.live.Get().MotionMinArea
 
 	for _, mr := range motion {
-		if mr.ContourArea < c.env.MotionMinArea {
+		if mr.ContourArea < minArea {
 			c.metrics.motionDetected.WithLabelValues("false").Add(mr.ContourArea)
 			continue
 		}
@@ -1,6 +1,9 @@
 package cfg
 
-import "time"
+import (
+	"github.com/minor-industries/theheads/config"
+	"time"
+)
 
 type Floodlight struct {
 	Pin int // 17 for heads
@@ -20,9 +23,9 @@ type Cfg struct {
 	Hflip              bool
 	Instance           string
 	MotionDetectWidth  int
-	MotionMinArea      float64
+	MotionMinArea      float64 `reload:"true"`
 	MotionShutoffDelay time.Duration
-	MotionThreshold    int
+	MotionThreshold    int `reload:"true"`
 	Outdir             string
 	Port               int
 	PrescaleWidth      int
@@ -37,3 +40,17 @@ type Cfg struct {
 	Width              int
 	WriteFacesPath     string `envconfig:"optional"`
 }
+
+func (c *Cfg) Validate() error {
+	check := &config.Check{}
+	check.That(c.Instance != "", "Instance is required")
+	check.That(c.Port > 0 && c.Port < 65536, "Port %d is out of range", c.Port)
+	check.That(c.Width > 0 && c.Height > 0, "Width and Height must be positive")
+	check.That(c.Framerate > 0, "Framerate must be positive")
+	check.That(c.Source != "", "Source is required")
+	check.That(c.MotionDetectWidth > 0, "MotionDetectWidth must be positive")
+	check.That(c.MotionThreshold > 0 && c.MotionThreshold < 256, "MotionThreshold must be between 1 and 255")
+	check.That(c.MotionMinArea >= 0, "MotionMinArea can't be negative")
+	check.That(c.FloodlightPin >= 0, "FloodlightPin can't be negative")
+	return check.Err()
+}
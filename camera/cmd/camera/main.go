@@ -3,9 +3,10 @@ package main
 import (
 	_ "embed"
 	"github.com/minor-industries/platform/common/dotenv"
+	"github.com/minor-industries/platform/common/util"
 	"github.com/minor-industries/theheads/camera"
 	"github.com/minor-industries/theheads/camera/cfg"
-	"github.com/vrischmann/envconfig"
+	"github.com/minor-industries/theheads/config"
 )
 
 //go:embed default.env
@@ -19,10 +20,18 @@ func main() {
 
 	env := &cfg.Cfg{}
 
-	err = envconfig.Init(env)
+	path := config.Path("camera")
+	err = config.Load(path, env)
 	if err != nil {
 		panic(err)
 	}
 
-	camera.Run(env)
+	logger, err := util.NewLogger(false)
+	if err != nil {
+		panic(err)
+	}
+	live := config.NewLive(env)
+	go config.ReloadOnSIGHUP(logger, path, live)
+
+	camera.Run(live)
 }
@@ -2,6 +2,12 @@ module github.com/minor-industries/theheads/camera
 
 go 1.20
 
+replace (
+	github.com/minor-industries/protobuf => ../../protobuf
+	github.com/minor-industries/theheads/config => ../config
+	github.com/minor-industries/theheads/schedule => ../schedule
+)
+
 require (
 	github.com/gin-contrib/pprof v1.4.0
 	github.com/gin-gonic/gin v1.9.1
@@ -10,7 +16,10 @@ require (
 	github.com/minor-industries/packager v0.0.1
 	github.com/minor-industries/platform v0.0.3
 	github.com/minor-industries/protobuf v0.0.1
+	github.com/minor-industries/theheads/config v0.0.0-00010101000000-000000000000
+	github.com/minor-industries/theheads/schedule v0.0.0-00010101000000-000000000000
 	github.com/montanaflynn/stats v0.7.1
+	github.com/pelletier/go-toml/v2 v2.0.8
 	github.com/pixiv/go-libjpeg v0.0.0-20190822045933-3da21a74767d
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus/client_golang v1.16.0
@@ -20,6 +29,7 @@ require (
 	go.uber.org/zap v1.25.0
 	gocv.io/x/gocv v0.35.0
 	google.golang.org/grpc v1.57.0
+	google.golang.org/protobuf v1.31.0
 )
 
 require (
@@ -50,7 +60,6 @@ require (
 	github.com/matttproud/golang_protobuf_extensions v1.0.4 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
-	github.com/pelletier/go-toml/v2 v2.0.8 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/prometheus/client_model v0.3.0 // indirect
 	github.com/prometheus/common v0.42.0 // indirect
@@ -67,7 +76,6 @@ require (
 	golang.org/x/text v0.12.0 // indirect
 	gonum.org/v1/gonum v0.13.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20230525234030-28d5490b6b19 // indirect
-	google.golang.org/protobuf v1.31.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	mvdan.cc/sh/v3 v3.6.0 // indirect
 )
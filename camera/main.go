@@ -5,10 +5,13 @@ import (
 	"github.com/minor-industries/platform/common/util"
 	"github.com/minor-industries/theheads/camera/cfg"
 	"github.com/minor-industries/theheads/camera/floodlight"
+	"github.com/minor-industries/theheads/config"
 	"go.uber.org/zap"
 )
 
-func Run(env *cfg.Cfg) {
+func Run(live *config.Live[cfg.Cfg]) {
+	env := live.Get()
+
 	logger, err := util.NewLogger(false)
 	if err != nil {
 		panic(err)
@@ -30,7 +33,7 @@ func Run(env *cfg.Cfg) {
 
 	c := NewCamera(
 		logger,
-		env,
+		live,
 		msgBroker,
 		fl,
 	)
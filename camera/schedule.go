@@ -0,0 +1,25 @@
+package camera
+
+import (
+	"github.com/minor-industries/protobuf/gen/go/heads"
+	"github.com/minor-industries/theheads/schedule"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newScheduler serves s to boss and the other hosts over the shared protos
+func newScheduler(s *schedule.Scheduler) heads.SchedulerServer {
+	return schedule.NewServer[*heads.ScheduleIn, *heads.CancelIn, *heads.Empty](
+		s,
+		schedule.Messages[*heads.Scheduled, *heads.CancelOut, *heads.ScheduledList]{
+			Scheduled: func(id string, at *timestamppb.Timestamp, method string) *heads.Scheduled {
+				return &heads.Scheduled{Id: id, At: at, Method: method}
+			},
+			Cancelled: func(n int32) *heads.CancelOut {
+				return &heads.CancelOut{Cancelled: n}
+			},
+			List: func(scheduled []*heads.Scheduled) *heads.ScheduledList {
+				return &heads.ScheduledList{Scheduled: scheduled}
+			},
+		},
+	)
+}
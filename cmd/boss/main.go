@@ -4,10 +4,11 @@ import (
 	_ "embed"
 	"github.com/minor-industries/platform/common/discovery"
 	"github.com/minor-industries/platform/common/dotenv"
+	"github.com/minor-industries/platform/common/util"
 	"github.com/minor-industries/theheads/boss"
 	"github.com/minor-industries/theheads/boss/cfg"
+	"github.com/minor-industries/theheads/config"
 	"github.com/pkg/errors"
-	"github.com/vrischmann/envconfig"
 )
 
 //go:embed default.env
@@ -21,12 +22,20 @@ func run() error {
 		panic(err)
 	}
 
-	err = envconfig.Init(env)
+	path := config.Path("boss")
+	err = config.Load(path, env)
 	if err != nil {
-		return errors.Wrap(err, "init env")
+		return errors.Wrap(err, "load config")
 	}
 
-	boss.Run(env, discovery.NewSerf("127.0.0.1:7373"))
+	logger, err := util.NewLogger(env.Debug)
+	if err != nil {
+		return errors.Wrap(err, "new logger")
+	}
+	live := config.NewLive(env)
+	go config.ReloadOnSIGHUP(logger, path, live)
+
+	boss.Run(live, discovery.NewSerf("127.0.0.1:7373"))
 	return nil
 }
 
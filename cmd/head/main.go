@@ -1,15 +1,15 @@
 package main
 
 import (
+	"github.com/minor-industries/theheads/config"
 	"github.com/minor-industries/theheads/head"
 	"github.com/minor-industries/theheads/head/cfg"
-	"github.com/vrischmann/envconfig"
 )
 
 func main() {
 	env := &cfg.Cfg{}
 
-	err := envconfig.Init(env)
+	err := config.Load(config.Path("head"), env)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"github.com/vrischmann/envconfig"
+	"go.uber.org/zap"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const configHome = "/etc/env"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Validator is implemented by configs which can check themselves after loading
+type Validator interface {
+	Validate() error
+}
+
+// Path returns the config file for a component, /etc/env/<component>.toml unless
+// overridden with CONFIG_FILE
+func Path(component string) string {
+	if p := os.Getenv("CONFIG_FILE"); p != "" {
+		return p
+	}
+	return filepath.Join(configHome, component+".toml")
+}
+
+// Load fills cfg from its envconfig defaults and the environment, then from the TOML
+// file at path if it exists. Keys in the file are the struct field names, e.g.
+//
+//	MotionThreshold = 8
+//	SpawnPeriod = "250ms"
+//
+//	[Motor]
+//	NumSteps = 200
+func Load(path string, cfg any) error {
+	err := envconfig.InitWithOptions(cfg, envconfig.Options{AllOptional: true})
+	if err != nil {
+		return errors.Wrap(err, "env")
+	}
+
+	content, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// environment only
+	case err != nil:
+		return errors.Wrap(err, "readfile")
+	default:
+		if err := apply(content, cfg); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if v, ok := cfg.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func apply(content []byte, cfg any) error {
+	src := map[string]any{}
+	err := toml.NewDecoder(bytes.NewBuffer(content)).Decode(&src)
+	if err != nil {
+		var de *toml.DecodeError
+		if errors.As(err, &de) {
+			return errors.New("\n" + de.String())
+		}
+		return err
+	}
+
+	var problems []string
+	setStruct("", src, reflect.ValueOf(cfg).Elem(), &problems)
+	if len(problems) > 0 {
+		return errors.New("\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func setStruct(path string, src map[string]any, dst reflect.Value, problems *[]string) {
+	keys := make([]string, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := key
+		if path != "" {
+			name = path + "." + key
+		}
+
+		f, ok := dst.Type().FieldByName(key)
+		if !ok || !f.IsExported() {
+			*problems = append(*problems, fmt.Sprintf("%s: unknown setting", name))
+			continue
+		}
+
+		if err := set(name, src[key], dst.FieldByIndex(f.Index), problems); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+}
+
+func set(name string, val any, dst reflect.Value, problems *[]string) error {
+	if dst.Type() == durationType {
+		switch v := val.(type) {
+		case string:
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", v)
+			}
+			dst.SetInt(int64(d))
+			return nil
+		default:
+			return fmt.Errorf("expected a duration like \"250ms\", got %v", val)
+		}
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		m, ok := val.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected a table, got %v", val)
+		}
+		setStruct(name, m, dst, problems)
+
+	case reflect.String:
+		v, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %v", val)
+		}
+		dst.SetString(v)
+
+	case reflect.Bool:
+		v, ok := val.(bool)
+		if !ok {
+			return fmt.Errorf("expected true or false, got %v", val)
+		}
+		dst.SetBool(v)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, ok := val.(int64)
+		if !ok {
+			return fmt.Errorf("expected an integer, got %v", val)
+		}
+		if dst.OverflowInt(v) {
+			return fmt.Errorf("%d is out of range", v)
+		}
+		dst.SetInt(v)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, ok := val.(int64)
+		if !ok || v < 0 || dst.OverflowUint(uint64(v)) {
+			return fmt.Errorf("expected a non-negative integer, got %v", val)
+		}
+		dst.SetUint(uint64(v))
+
+	case reflect.Float32, reflect.Float64:
+		switch v := val.(type) {
+		case float64:
+			dst.SetFloat(v)
+		case int64:
+			dst.SetFloat(float64(v))
+		default:
+			return fmt.Errorf("expected a number, got %v", val)
+		}
+
+	case reflect.Slice:
+		items, ok := val.([]any)
+		if !ok {
+			return fmt.Errorf("expected an array, got %v", val)
+		}
+		s := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := set(fmt.Sprintf("%s[%d]", name, i), item, s.Index(i), problems); err != nil {
+				return fmt.Errorf("[%d]: %s", i, err)
+			}
+		}
+		dst.Set(s)
+
+	default:
+		return fmt.Errorf("unsupported setting type %s", dst.Type())
+	}
+
+	return nil
+}
+
+// Live is a config which can be reloaded while the service runs. A reload swaps in a
+// new copy rather than changing the current one, so read reloadable settings through
+// Get each time they're used instead of holding on to the *T.
+type Live[T any] struct {
+	current atomic.Pointer[T]
+}
+
+func NewLive[T any](cfg *T) *Live[T] {
+	l := &Live[T]{}
+	l.current.Store(cfg)
+	return l
+}
+
+func (l *Live[T]) Get() *T {
+	return l.current.Load()
+}
+
+// ReloadOnSIGHUP re-reads the config on SIGHUP and publishes a copy of the live cfg
+// with the fields tagged `reload:"true"` taken from the file. Changes to any other
+// field are logged and need a restart.
+func ReloadOnSIGHUP[T any](logger *zap.Logger, path string, live *Live[T]) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+
+	for range ch {
+		logger := logger.With(zap.String("path", path))
+
+		fresh := new(T)
+		if err := Load(path, fresh); err != nil {
+			logger.Error("error reloading config, keeping the old one", zap.Error(err))
+			continue
+		}
+
+		live.reload(logger, fresh)
+		logger.Info("reloaded config")
+	}
+}
+
+func (l *Live[T]) reload(logger *zap.Logger, fresh *T) {
+	next := *l.Get()
+	copyReloadable(logger, "", reflect.ValueOf(fresh).Elem(), reflect.ValueOf(&next).Elem())
+	l.current.Store(&next)
+}
+
+func copyReloadable(logger *zap.Logger, path string, src, dst reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		f := src.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if path != "" {
+			name = path + "." + f.Name
+		}
+
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			copyReloadable(logger, name, src.Field(i), dst.Field(i))
+			continue
+		}
+
+		if reflect.DeepEqual(src.Field(i).Interface(), dst.Field(i).Interface()) {
+			continue
+		}
+
+		if f.Tag.Get("reload") != "true" {
+			logger.Warn("setting changed but needs a restart", zap.String("setting", name))
+			continue
+		}
+
+		logger.Info(
+			"updating setting",
+			zap.String("setting", name),
+			zap.Any("old", dst.Field(i).Interface()),
+			zap.Any("new", src.Field(i).Interface()),
+		)
+		dst.Field(i).Set(src.Field(i))
+	}
+}
+
+// Check collects validation problems so they can all be reported at once
+type Check struct {
+	problems []string
+}
+
+func (c *Check) That(ok bool, format string, args ...any) {
+	if !ok {
+		c.problems = append(c.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *Check) Err() error {
+	if len(c.problems) == 0 {
+		return nil
+	}
+	return errors.New("\n  " + strings.Join(c.problems, "\n  "))
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type motorCfg struct {
+	NumSteps int `envconfig:"default=200"`
+}
+
+type testCfg struct {
+	Instance  string
+	Period    time.Duration `envconfig:"default=250ms"`
+	Threshold int           `envconfig:"default=8" reload:"true"`
+	Addrs     []string      `envconfig:"default=1f;5e"`
+	Motor     motorCfg
+}
+
+func (c *testCfg) Validate() error {
+	check := &Check{}
+	check.That(c.Instance != "", "Instance is required")
+	check.That(c.Threshold > 0, "Threshold must be positive")
+	return check.Err()
+}
+
+func write(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "test.toml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := write(t, `
+Instance = "head-01"
+Period = "1s"
+Addrs = ["1f"]
+
+[Motor]
+NumSteps = 1036
+`)
+
+	c := &testCfg{}
+	require.NoError(t, Load(path, c))
+	assert.Equal(t, &testCfg{
+		Instance:  "head-01",
+		Period:    time.Second,
+		Threshold: 8,
+		Addrs:     []string{"1f"},
+		Motor:     motorCfg{NumSteps: 1036},
+	}, c)
+}
+
+func TestLoadErrors(t *testing.T) {
+	path := write(t, `
+Instance = "head-01"
+Period = 5
+Treshold = 3
+
+[Motor]
+NumSteps = "many"
+`)
+
+	err := Load(path, &testCfg{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `Motor.NumSteps: expected an integer, got many`)
+	assert.Contains(t, err.Error(), `Period: expected a duration like "250ms", got 5`)
+	assert.Contains(t, err.Error(), `Treshold: unknown setting`)
+
+	err = Load(write(t, `Threshold = 0`), &testCfg{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Instance is required")
+	assert.Contains(t, err.Error(), "Threshold must be positive")
+}
+
+func TestCopyReloadable(t *testing.T) {
+	live := &testCfg{Instance: "head-01", Threshold: 8, Motor: motorCfg{NumSteps: 200}}
+	fresh := &testCfg{Instance: "head-02", Threshold: 12, Motor: motorCfg{NumSteps: 1036}}
+
+	copyReloadable(zap.NewNop(), "", reflect.ValueOf(fresh).Elem(), reflect.ValueOf(live).Elem())
+
+	assert.Equal(t, 12, live.Threshold)
+	assert.Equal(t, "head-01", live.Instance)
+	assert.Equal(t, 200, live.Motor.NumSteps)
+}
+
+func TestLiveReload(t *testing.T) {
+	old := &testCfg{Instance: "head-01", Threshold: 8, Addrs: []string{"1f"}}
+	live := NewLive(old)
+
+	live.reload(zap.NewNop(), &testCfg{Instance: "head-02", Threshold: 12, Addrs: []string{"5e"}})
+
+	assert.Equal(t, 12, live.Get().Threshold)
+	assert.Equal(t, "head-01", live.Get().Instance)
+
+	// readers still holding the old snapshot don't see it change
+	assert.Equal(t, 8, old.Threshold)
+	assert.Equal(t, []string{"1f"}, old.Addrs)
+}
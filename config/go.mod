@@ -0,0 +1,18 @@
+module github.com/minor-industries/theheads/config
+
+go 1.20
+
+require (
+	github.com/pelletier/go-toml/v2 v2.0.8
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.8.4
+	github.com/vrischmann/envconfig v1.3.0
+	go.uber.org/zap v1.25.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
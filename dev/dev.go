@@ -4,7 +4,9 @@ import (
 	"github.com/minor-industries/platform/common/util"
 	cfg2 "github.com/minor-industries/theheads/boss/cfg"
 	"github.com/minor-industries/theheads/head/cfg"
+	"github.com/minor-industries/theheads/head/fallback"
 	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/sim"
 	"github.com/minor-industries/theheads/head/voices"
 	"os"
 	"time"
@@ -19,16 +21,48 @@ func headEnv(name string) *cfg.Cfg {
 		I2CBus:             "",
 		EnableMagnetSensor: false,
 		MagnetSensorAddrs:  nil,
+		DriftWindow:        15,
+		DriftTolerance:     2,
 		Motor: motor.Cfg{
 			NumSteps:              200,
 			StepSpeed:             30,
 			DirectionChangePauses: 10,
+			MaxVelocity:           45,
+			Acceleration:          90,
+			VelocityLimit:         120,
+			AccelerationLimit:     400,
+			ReleaseAfter:          2 * time.Minute,
+			ReleaseSlips:          true,
+		},
+		Sim: sim.Cfg{
+			PullIn:          60,
+			MaxVelocity:     150,
+			MaxAcceleration: 600,
+			MagnetWidth:     4,
+		},
+		Fallback: fallback.Cfg{
+			After:         30 * time.Second,
+			ScenePath:     "dev/scenes/two-heads",
+			SceneName:     "local-dev",
+			CameraAddr:    "localhost:5000",
+			Distance:      3,
+			VoiceInterval: 45 * time.Second,
 		},
 		Voices: voices.Cfg{
-			MediaPath: os.ExpandEnv("$HOME/shared/theheads/voices"),
+			MediaPath:   os.ExpandEnv("$HOME/shared/theheads/voices"),
+			MediaRescan: 30 * time.Second,
+			Mixer:       "fake",
+			Output:      "device",
+			QueueSize:   4,
+			LipSync:     false,
 		},
-		Debug:             false,
-		HeartbeatInterval: time.Second,
+		StateFile:             "", // the heads share the process, and dev handles the signals
+		StateSaveInterval:     10 * time.Second,
+		MagnetCalibrationFile: "",
+		EstimateInterval:      250 * time.Millisecond,
+		EstimateConfidence:    0.5,
+		Debug:                 false,
+		HeartbeatInterval:     time.Second,
 	}
 	return env
 }
@@ -46,6 +80,8 @@ func bossEnv() *cfg2.Cfg {
 		DayDetector:          []string{"time-based", "7h30m", "20h15m"},
 		Debug:                true,
 		CheckInTime:          500 * time.Millisecond,
+		HeartbeatInterval:    time.Second,
+		MissedHeartbeats:     5,
 		FearfulCount:         3,
 		VoiceVolume:          -100,
 	}
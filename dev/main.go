@@ -8,6 +8,7 @@ import (
 	"github.com/minor-industries/theheads/boss"
 	util2 "github.com/minor-industries/theheads/boss/util"
 	"github.com/minor-industries/theheads/camera"
+	"github.com/minor-industries/theheads/config"
 	"github.com/minor-industries/theheads/head"
 	"github.com/minor-industries/theheads/web"
 	"github.com/prometheus/client_golang/prometheus"
@@ -41,12 +42,12 @@ func main() {
 	wg.Add(1)
 	camera01Cfg := cameraEnv("camera-01", "dev/pi42.raw")
 	services.Register("camera", "camera-01", camera01Cfg.Port)
-	go camera.Run(camera01Cfg)
+	go camera.Run(config.NewLive(camera01Cfg))
 
 	wg.Add(1)
 	camera02Cfg := cameraEnv("camera-02", "dev/pi43.raw")
 	services.Register("camera", "camera-02", camera02Cfg.Port)
-	go camera.Run(camera02Cfg)
+	go camera.Run(config.NewLive(camera02Cfg))
 
 	services.Register("head", head01.Instance, head01.Port)
 	services.Register("head", head02.Instance, head02.Port)
@@ -67,7 +68,7 @@ func main() {
 
 	time.Sleep(50 * time.Millisecond)
 
-	go boss.Run(boss01, services)
+	go boss.Run(config.NewLive(boss01), services)
 
 	if false {
 		services.Register("web", "web01", 80)
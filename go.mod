@@ -9,14 +9,18 @@ replace (
 	github.com/minor-industries/protobuf => ../protobuf
 	github.com/minor-industries/rfm69 => ./rfm69
 	github.com/minor-industries/theheads/camera => ./camera
+	github.com/minor-industries/theheads/config => ./config
+	github.com/minor-industries/theheads/schedule => ./schedule
 )
 
 require (
 	github.com/ajstarks/svgo v0.0.0-20211024235047-1546f124cd8b
 	github.com/coreos/go-systemd v0.0.0-20191104093116-d3cd4ed1dbcf
+	github.com/ebitengine/oto/v3 v3.1.0
 	github.com/gin-contrib/pprof v1.4.0
 	github.com/gin-gonic/gin v1.9.1
 	github.com/goburrow/modbus v0.1.0
+	github.com/goburrow/serial v0.1.0
 	github.com/golang/protobuf v1.5.3
 	github.com/google/uuid v1.3.0
 	github.com/gorilla/websocket v1.5.0
@@ -30,6 +34,8 @@ require (
 	github.com/minor-industries/platform v0.0.3
 	github.com/minor-industries/protobuf v0.0.1
 	github.com/minor-industries/theheads/camera v0.0.0-00010101000000-000000000000
+	github.com/minor-industries/theheads/config v0.0.0-00010101000000-000000000000
+	github.com/minor-industries/theheads/schedule v0.0.0-00010101000000-000000000000
 	github.com/mitchellh/mapstructure v1.5.0
 	github.com/montanaflynn/stats v0.7.1
 	github.com/orcaman/concurrent-map/v2 v2.0.1
@@ -45,6 +51,7 @@ require (
 	go.uber.org/zap v1.25.0
 	gobot.io/x/gobot v1.16.0
 	golang.org/x/crypto v0.12.0
+	golang.org/x/sys v0.13.0
 	gonum.org/v1/gonum v0.13.0
 	gonum.org/v1/plot v0.13.0
 	google.golang.org/grpc v1.57.0
@@ -64,6 +71,7 @@ require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/chenzhuoyu/base64x v0.0.0-20221115062448-fe3a3abad311 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/ebitengine/purego v0.5.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.2 // indirect
 	github.com/gin-contrib/sse v0.1.0 // indirect
 	github.com/go-fonts/liberation v0.3.1 // indirect
@@ -74,7 +82,6 @@ require (
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/go-playground/validator/v10 v10.14.0 // indirect
 	github.com/go-sql-driver/mysql v1.7.1 // indirect
-	github.com/goburrow/serial v0.1.0 // indirect
 	github.com/goccy/go-json v0.10.2 // indirect
 	github.com/gofrs/uuid v4.0.0+incompatible // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
@@ -113,7 +120,6 @@ require (
 	golang.org/x/arch v0.3.0 // indirect
 	golang.org/x/image v0.7.0 // indirect
 	golang.org/x/net v0.10.0 // indirect
-	golang.org/x/sys v0.13.0 // indirect
 	golang.org/x/term v0.11.0 // indirect
 	golang.org/x/text v0.12.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20230525234030-28d5490b6b19 // indirect
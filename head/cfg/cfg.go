@@ -1,7 +1,13 @@
 package cfg
 
 import (
+	"github.com/minor-industries/theheads/config"
+	"github.com/minor-industries/theheads/head/fallback"
 	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/sim"
+	"github.com/minor-industries/theheads/head/motor/stepdir"
+	"github.com/minor-industries/theheads/head/motor/stepper"
+	"github.com/minor-industries/theheads/head/motor/tmc2209"
 	"github.com/minor-industries/theheads/head/voices"
 	"time"
 )
@@ -9,7 +15,7 @@ import (
 type Cfg struct {
 	Instance    string
 	Port        int  `envconfig:"default=8080"`
-	FakeStepper bool `envconfig:"optional"`
+	FakeStepper bool `envconfig:"optional"` // simulates the motor and sensors
 	SensorPin   int  `envconfig:"default=21"`
 
 	I2CBus string `envconfig:"default=1"`
@@ -17,10 +23,107 @@ type Cfg struct {
 	EnableMagnetSensor bool     `envconfig:"default=true"`
 	MagnetSensorAddrs  []string `envconfig:"default=1f;5e"` // note semicolon to separate default values
 
-	Motor  motor.Cfg
-	Voices voices.Cfg
+	// temperature compensation for the magnet sensor, see the calibrate_magnet rpc
+	MagnetCalibrationFile string `envconfig:"default=/var/lib/theheads/magnet-calibration.json"`
+
+	// once calibrated while zeroed, the magnet sensor estimates the head's angle, and a
+	// head started with a confident enough estimate skips finding zero
+	EstimateInterval   time.Duration `envconfig:"default=250ms"`
+	EstimateConfidence float64       `envconfig:"default=0.5"`
+
+	// once zeroed, the magnet sensor checks the position every time the head passes zero
+	DriftWindow    int  `envconfig:"default=15"` // steps either side of zero
+	DriftTolerance int  `envconfig:"default=2"`
+	DriftRezero    bool `envconfig:"default=true"`
+
+	// hat for the Adafruit motor hat, or stepdir for drivers such as the A4988 and TMC2209
+	Driver       string `envconfig:"default=hat"`
+	HatStepStyle string `envconfig:"default=single"` // single, double, interleave or microstep
+	StepDir      stepdir.Cfg
+	TMC2209      tmc2209.Cfg
+
+	Motor    motor.Cfg
+	Voices   voices.Cfg
+	Sim      sim.Cfg
+	Fallback fallback.Cfg // for when boss is unreachable
+
+	// the head's position is kept here across restarts; empty to start from scratch
+	StateFile         string        `envconfig:"default=/var/lib/theheads/head-state.json"`
+	StateMaxAge       time.Duration `envconfig:"default=12h"`
+	StateSaveInterval time.Duration `envconfig:"default=10s"`
 
 	Debug bool `envconfig:"optional"`
 
 	HeartbeatInterval time.Duration `envconfig:"default=1s"`
 }
+
+func (c *Cfg) Validate() error {
+	check := &config.Check{}
+	check.That(c.Instance != "", "Instance is required")
+	check.That(c.Port > 0 && c.Port < 65536, "Port %d is out of range", c.Port)
+	check.That(c.Motor.NumSteps > 0, "Motor.NumSteps must be positive")
+	check.That(c.Motor.StepSpeed > 0, "Motor.StepSpeed must be positive")
+	check.That(c.Motor.MaxVelocity > 0, "Motor.MaxVelocity must be positive")
+	check.That(c.Motor.Acceleration > 0, "Motor.Acceleration must be positive")
+	check.That(c.Motor.VelocityLimit >= c.Motor.MaxVelocity, "Motor.VelocityLimit can't be below MaxVelocity")
+	check.That(
+		c.Motor.AccelerationLimit >= c.Motor.Acceleration,
+		"Motor.AccelerationLimit can't be below Acceleration",
+	)
+	check.That(c.Motor.DirectionChangePauses >= 0, "Motor.DirectionChangePauses can't be negative")
+	check.That(c.Motor.ReleaseAfter >= 0, "Motor.ReleaseAfter can't be negative")
+	if !c.FakeStepper {
+		check.That(c.Driver == "hat" || c.Driver == "stepdir", "unknown Driver %q", c.Driver)
+	}
+	if !c.FakeStepper && c.Driver == "hat" {
+		check.That(stepper.CheckStyle(c.HatStepStyle) == nil, "unknown HatStepStyle %q", c.HatStepStyle)
+	}
+	if !c.FakeStepper && c.Driver == "stepdir" {
+		check.That(
+			stepdir.CheckMicrosteps(c.StepDir.Microsteps) == nil,
+			"StepDir.Microsteps must be a power of two up to 256",
+		)
+		check.That(c.StepDir.PulseWidth > 0, "StepDir.PulseWidth must be positive")
+		check.That(c.TMC2209.Address >= 0 && c.TMC2209.Address <= 3, "TMC2209.Address must be from 0 to 3")
+		check.That(
+			c.TMC2209.HoldCurrent <= c.TMC2209.RunCurrent,
+			"TMC2209.HoldCurrent can't be above RunCurrent",
+		)
+	}
+	if c.FakeStepper {
+		check.That(c.Sim.PullIn > 0, "Sim.PullIn must be positive")
+		check.That(c.Sim.MaxVelocity >= c.Sim.PullIn, "Sim.MaxVelocity can't be below PullIn")
+		check.That(c.Sim.MaxAcceleration > 0, "Sim.MaxAcceleration must be positive")
+		check.That(c.Sim.MagnetWidth > 0, "Sim.MagnetWidth must be positive")
+	}
+	check.That(
+		c.Voices.Output == "device" || c.Voices.Output == "null",
+		"unknown Voices.Output %q", c.Voices.Output,
+	)
+	check.That(c.Voices.QueueSize > 0, "Voices.QueueSize must be positive")
+	check.That(
+		c.Voices.Mixer == "device" || c.Voices.Mixer == "fake",
+		"unknown Voices.Mixer %q", c.Voices.Mixer,
+	)
+	check.That(c.Voices.MediaRescan > 0, "Voices.MediaRescan must be positive")
+	check.That(
+		c.DriftWindow >= 3 && c.DriftWindow < c.Motor.NumSteps/2,
+		"DriftWindow must be at least 3 steps and under half a turn",
+	)
+	check.That(c.DriftTolerance >= 0, "DriftTolerance can't be negative")
+	check.That(c.EstimateInterval > 0, "EstimateInterval must be positive")
+	check.That(
+		c.EstimateConfidence >= 0 && c.EstimateConfidence <= 1,
+		"EstimateConfidence must be from 0 to 1",
+	)
+	check.That(c.Fallback.After >= 0, "Fallback.After can't be negative")
+	check.That(c.Fallback.Distance > 0, "Fallback.Distance must be positive")
+	check.That(c.Fallback.VoiceInterval > 0, "Fallback.VoiceInterval must be positive")
+	check.That(c.StateSaveInterval > 0, "StateSaveInterval must be positive")
+	check.That(c.HeartbeatInterval > 0, "HeartbeatInterval must be positive")
+	check.That(
+		!c.EnableMagnetSensor || len(c.MagnetSensorAddrs) > 0,
+		"MagnetSensorAddrs is required when EnableMagnetSensor is set",
+	)
+	return check.Err()
+}
@@ -0,0 +1,59 @@
+package head
+
+import (
+	"fmt"
+	"github.com/minor-industries/theheads/head/cfg"
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/stepdir"
+	"github.com/minor-industries/theheads/head/motor/stepper"
+	"github.com/minor-industries/theheads/head/motor/tmc2209"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+func newDriver(logger *zap.Logger, env *cfg.Cfg) (motor.Motor, error) {
+	switch env.Driver {
+	case "hat":
+		m, err := stepper.New(logger, env.Motor.MotorID, env.HatStepStyle)
+		if err != nil {
+			return nil, errors.Wrap(err, "hat")
+		}
+		return m, nil
+
+	case "stepdir":
+		if env.TMC2209.Port != "" {
+			if err := configureTMC2209(logger, env); err != nil {
+				return nil, errors.Wrap(err, "configure tmc2209")
+			}
+		}
+		m, err := stepdir.Open(&env.StepDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "step/dir")
+		}
+		return m, nil
+
+	default:
+		return nil, fmt.Errorf("unknown driver %q", env.Driver)
+	}
+}
+
+func configureTMC2209(logger *zap.Logger, env *cfg.Cfg) error {
+	driver, port, err := tmc2209.Open(&env.TMC2209)
+	if err != nil {
+		return err
+	}
+	defer port.Close()
+
+	settings := tmc2209.Settings{
+		RunCurrent:  env.TMC2209.RunCurrent,
+		HoldCurrent: env.TMC2209.HoldCurrent,
+		StealthChop: env.TMC2209.StealthChop,
+		Microsteps:  env.StepDir.Microsteps,
+	}
+	if err := driver.Configure(settings); err != nil {
+		return err
+	}
+
+	logger.Info("configured tmc2209", zap.Any("settings", settings))
+	return nil
+}
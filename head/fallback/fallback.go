@@ -0,0 +1,237 @@
+package fallback
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/minor-industries/platform/schema"
+	"github.com/minor-industries/protobuf/gen/go/heads"
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/look_around"
+	"github.com/minor-industries/theheads/head/motor/seeker"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"math/rand"
+	"os"
+	"time"
+)
+
+type Cfg struct {
+	// how long without heartbeat acks before the head takes over from boss; 0 never does
+	After time.Duration `envconfig:"default=30s"`
+
+	// the scenes directory, which is synced to every host, for where the cameras are
+	ScenePath string `envconfig:"optional"`
+	SceneName string `envconfig:"default=prod"`
+
+	CameraAddr    string        `envconfig:"default=localhost:5000"` // the camera on the head's stand
+	Distance      float64       `envconfig:"default=3"`              // how far out along the camera's bearing to look
+	VoiceInterval time.Duration `envconfig:"default=45s"`            // on average, between random voices
+}
+
+const tickPeriod = 250 * time.Millisecond
+
+type Acks interface {
+	LastAck() time.Time
+}
+
+type Controller interface {
+	SetActor(actor motor.Actor)
+	ResetActor()
+	SetTargetRotation(target float64)
+}
+
+// Fallback keeps the head going while boss can't be reached: it follows the motion seen
+// by the camera on its own stand and says something now and then. Boss gets control back
+// as soon as it acks a heartbeat again.
+type Fallback struct {
+	logger     *zap.Logger
+	cfg        *Cfg
+	numSteps   int
+	acks       Acks
+	controller Controller
+	say        func(ctx context.Context) error
+
+	layout *layout // nil without a scene, when the head can only look around
+	motion chan *schema.MotionDetected
+
+	active bool
+	cancel context.CancelFunc
+}
+
+func New(
+	logger *zap.Logger,
+	cfg *Cfg,
+	instance string,
+	numSteps int,
+	acks Acks,
+	controller Controller,
+	say func(ctx context.Context) error,
+) *Fallback {
+	f := &Fallback{
+		logger:     logger,
+		cfg:        cfg,
+		numSteps:   numSteps,
+		acks:       acks,
+		controller: controller,
+		say:        say,
+		motion:     make(chan *schema.MotionDetected, 1),
+	}
+
+	if cfg.ScenePath != "" {
+		l, err := loadLayout(os.ExpandEnv(cfg.ScenePath), cfg.SceneName, instance)
+		if err != nil {
+			logger.Warn("fallback can't follow the camera", zap.Error(err))
+		}
+		f.layout = l
+	}
+
+	return f
+}
+
+func (f *Fallback) Run() {
+	if f.cfg.After == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(tickPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			f.tick(now)
+		case msg := <-f.motion:
+			f.track(msg)
+		}
+	}
+}
+
+func (f *Fallback) tick(now time.Time) {
+	quiet := now.Sub(f.acks.LastAck())
+
+	switch {
+	case !f.active && quiet >= f.cfg.After:
+		f.start(quiet)
+	case f.active && quiet < f.cfg.After:
+		f.stop()
+	}
+
+	if f.active && rand.Float64() < tickPeriod.Seconds()/f.cfg.VoiceInterval.Seconds() {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			if err := f.say(ctx); err != nil {
+				f.logger.Debug("fallback couldn't say anything", zap.Error(err))
+			}
+		}()
+	}
+}
+
+func (f *Fallback) start(quiet time.Duration) {
+	f.logger.Warn("boss is unreachable, taking over", zap.Duration("quiet", quiet))
+	f.active = true
+	gActive.Set(1)
+
+	if f.layout == nil {
+		actor, err := look_around.New(f.numSteps, nil)
+		if err != nil {
+			panic(err)
+		}
+		f.controller.SetActor(actor)
+		return
+	}
+
+	f.controller.SetActor(seeker.New(f.numSteps))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	go f.stream(ctx)
+}
+
+// stop hands the head back to boss in the state it started in, until boss sets its own
+// actor with the next scene
+func (f *Fallback) stop() {
+	f.logger.Info("boss is back, handing over")
+	f.active = false
+	gActive.Set(0)
+
+	if f.cancel != nil {
+		f.cancel()
+		f.cancel = nil
+	}
+	f.controller.ResetActor()
+}
+
+func (f *Fallback) track(msg *schema.MotionDetected) {
+	if !f.active {
+		return // left over from before boss came back
+	}
+
+	if f.layout == nil {
+		return
+	}
+
+	theta, ok := f.layout.aim(msg.CameraName, msg.Position, f.cfg.Distance)
+	if !ok {
+		return
+	}
+	f.controller.SetTargetRotation(theta)
+}
+
+func (f *Fallback) stream(ctx context.Context) {
+	logger := f.logger.With(zap.String("addr", f.cfg.CameraAddr))
+
+	for {
+		err := f.streamOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.Info("fallback camera streaming error", zap.Error(err))
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (f *Fallback) streamOnce(ctx context.Context) error {
+	conn, err := grpc.Dial(f.cfg.CameraAddr, grpc.WithInsecure())
+	if err != nil {
+		return errors.Wrap(err, "dial")
+	}
+	defer conn.Close()
+
+	events, err := heads.NewEventsClient(conn).Stream(ctx, &heads.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "stream")
+	}
+
+	for {
+		event, err := events.Recv()
+		if err != nil {
+			return errors.Wrap(err, "recv")
+		}
+		if event.Type != "motion-detected" {
+			continue
+		}
+
+		msg := &schema.MotionDetected{}
+		if err := json.Unmarshal([]byte(event.Data), msg); err != nil {
+			return errors.Wrap(err, "unmarshal")
+		}
+
+		// only the latest bearing matters
+		select {
+		case <-f.motion:
+		default:
+		}
+		select {
+		case f.motion <- msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
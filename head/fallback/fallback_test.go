@@ -0,0 +1,83 @@
+package fallback
+
+import (
+	"context"
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type acks struct {
+	last time.Time
+}
+
+func (a *acks) LastAck() time.Time {
+	return a.last
+}
+
+type controller struct {
+	actors []string
+}
+
+func (c *controller) SetActor(actor motor.Actor) {
+	c.actors = append(c.actors, actor.Name())
+}
+
+func (c *controller) ResetActor() {
+	c.actors = append(c.actors, "default")
+}
+
+func (c *controller) SetTargetRotation(target float64) {}
+
+func TestFallback(t *testing.T) {
+	start := time.Date(2022, 8, 1, 22, 0, 0, 0, time.UTC)
+	a := &acks{last: start}
+	c := &controller{}
+	var said int32
+
+	f := New(
+		zap.NewNop(),
+		&Cfg{After: 30 * time.Second, Distance: 3, VoiceInterval: time.Nanosecond},
+		"head-01",
+		200,
+		a,
+		c,
+		func(ctx context.Context) error {
+			atomic.AddInt32(&said, 1)
+			return nil
+		},
+	)
+
+	f.tick(start.Add(10 * time.Second))
+	assert.False(t, f.active)
+
+	f.tick(start.Add(30 * time.Second))
+	assert.True(t, f.active)
+	assert.Equal(t, []string{"LookAround"}, c.actors, "there's no scene to aim with")
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&said) > 0 }, time.Second, time.Millisecond)
+
+	f.tick(start.Add(40 * time.Second))
+	assert.Len(t, c.actors, 1, "only set on taking over")
+
+	// boss is back
+	a.last = start.Add(41 * time.Second)
+	f.tick(start.Add(41 * time.Second))
+	assert.False(t, f.active)
+	assert.Equal(t, []string{"LookAround", "default"}, c.actors, "hands back in the default actor")
+}
+
+func TestLoadLayout(t *testing.T) {
+	l, err := loadLayout("../../dev/scenes/two-heads", "local-dev", "head-01")
+	require.NoError(t, err)
+	assert.NotEmpty(t, l.cameras)
+
+	_, ok := l.aim("no-such-camera", 0, 3)
+	assert.False(t, ok)
+
+	_, err = loadLayout("../../dev/scenes/two-heads", "local-dev", "head-99")
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package fallback
+
+import (
+	"bytes"
+	"github.com/minor-industries/platform/common/geom"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"math"
+	"os"
+	"path"
+)
+
+// layout is the little the fallback needs from the scene: where the cameras on the
+// head's stand point, and where the head itself sits. It's read straight from the scene
+// file, which keeps the head from depending on boss.
+type layout struct {
+	cameras map[string]geom.Mat // camera to world, by camera name
+	headInv geom.Mat            // world to head
+}
+
+type placement struct {
+	Name string
+	Pos  struct{ X, Y float64 }
+	Rot  float64
+}
+
+func (p placement) m() geom.Mat {
+	return geom.ToM(p.Pos.X, p.Pos.Y, p.Rot)
+}
+
+type sceneFile struct {
+	Stands []struct {
+		placement
+		CameraNames []string
+		HeadNames   []string
+	}
+	Cameras []placement
+	Heads   []placement
+}
+
+func loadLayout(scenePath, sceneName, instance string) (*layout, error) {
+	content, err := os.ReadFile(path.Join(scenePath, sceneName+".toml"))
+	if err != nil {
+		return nil, errors.Wrap(err, "read scene")
+	}
+
+	sc := &sceneFile{}
+	if err := toml.NewDecoder(bytes.NewBuffer(content)).Decode(sc); err != nil {
+		return nil, errors.Wrap(err, "decode scene")
+	}
+
+	byName := func(ps []placement) map[string]placement {
+		result := map[string]placement{}
+		for _, p := range ps {
+			result[p.Name] = p
+		}
+		return result
+	}
+	cameras, heads := byName(sc.Cameras), byName(sc.Heads)
+
+	for _, stand := range sc.Stands {
+		for _, name := range stand.HeadNames {
+			if name != instance {
+				continue
+			}
+
+			head, ok := heads[name]
+			if !ok {
+				return nil, errors.Errorf("%s not found", name)
+			}
+
+			standM := stand.m()
+			l := &layout{
+				cameras: map[string]geom.Mat{},
+				headInv: standM.Mul(head.m()).Inv(),
+			}
+			for _, camName := range stand.CameraNames {
+				camera, ok := cameras[camName]
+				if !ok {
+					return nil, errors.Errorf("%s not found", camName)
+				}
+				l.cameras[camName] = standM.Mul(camera.m())
+			}
+			return l, nil
+		}
+	}
+
+	return nil, errors.Errorf("%s isn't in the scene", instance)
+}
+
+// aim is the rotation which points the head at the spot distance out along a camera's
+// bearing
+func (l *layout) aim(cameraName string, bearing, distance float64) (float64, bool) {
+	m, ok := l.cameras[cameraName]
+	if !ok {
+		return 0, false
+	}
+
+	p := m.MulVec(geom.Rotz(bearing).MulVec(geom.NewVec(distance, 0)))
+	to := l.headInv.MulVec(p)
+	theta := math.Atan2(to.Y(), to.X()) * 180 / math.Pi
+	return math.Mod(theta+360, 360), true
+}
@@ -0,0 +1,14 @@
+package fallback
+
+import (
+	"github.com/minor-industries/platform/common/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	gActive = metrics.SimpleGauge(
+		prometheus.DefaultRegisterer,
+		"head",
+		"fallback_active",
+	)
+)
@@ -0,0 +1,6 @@
+package fe
+
+import "embed"
+
+//go:embed *.html
+var FS embed.FS
@@ -6,16 +6,26 @@ import (
 	"github.com/minor-industries/protobuf/gen/go/heads"
 	"github.com/minor-industries/theheads/head/log_limiter"
 	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/breathe"
+	"github.com/minor-industries/theheads/head/motor/double_take"
 	"github.com/minor-industries/theheads/head/motor/jitter"
+	"github.com/minor-industries/theheads/head/motor/look_around"
 	"github.com/minor-industries/theheads/head/motor/seeker"
+	"github.com/minor-industries/theheads/head/motor/sweep"
+	"github.com/minor-industries/theheads/head/motor/timeline"
 	"github.com/minor-industries/theheads/head/motor/zero_detector"
 	"github.com/minor-industries/theheads/head/sensor"
 	"github.com/minor-industries/theheads/head/sensor/magnetometer"
+	"github.com/minor-industries/theheads/head/sensor/magnetometer/calibrator"
 	zero_detector2 "github.com/minor-industries/theheads/head/sensor/magnetometer/zero_detector"
 	cmap "github.com/orcaman/concurrent-map/v2"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
 )
 
 type Handler struct {
@@ -23,12 +33,14 @@ type Handler struct {
 	limiter    *log_limiter.Limiter
 	logger     *zap.Logger
 
-	actors map[string]motor.Actor
+	actors map[string]motor.ActorFactory
 	sensor sensor.Sensor
 
-	motorCfg     *motor.Cfg
-	magnetometer magnetometer.Sensor
-	svgs         cmap.ConcurrentMap[string, []byte]
+	motorCfg        *motor.Cfg
+	magnetometer    *magnetometer.Compensated
+	estimator       *magnetometer.Estimator
+	calibrationFile string
+	svgs            cmap.ConcurrentMap[string, []byte]
 }
 
 func NewHandler(
@@ -36,27 +48,45 @@ func NewHandler(
 	limiter *log_limiter.Limiter,
 	logger *zap.Logger,
 	sensor sensor.Sensor,
-	magnetometer magnetometer.Sensor,
+	magnetometer *magnetometer.Compensated,
+	estimator *magnetometer.Estimator,
+	calibrationFile string,
 	motorCfg *motor.Cfg,
 	svgs cmap.ConcurrentMap[string, []byte],
 ) *Handler {
-	actors := map[string]motor.Actor{}
-
-	seeker := seeker.New(motorCfg.NumSteps)
-	actors[seeker.Name()] = seeker
-
-	jitter := jitter.New(motorCfg.NumSteps)
-	actors[jitter.Name()] = jitter
+	numSteps := motorCfg.NumSteps
+	actors := map[string]motor.ActorFactory{
+		"Seeker": func(params motor.Params) (motor.Actor, error) {
+			return seeker.New(numSteps), params.Check()
+		},
+		"Jitter": func(params motor.Params) (motor.Actor, error) {
+			return jitter.New(numSteps, params)
+		},
+		"Sweep": func(params motor.Params) (motor.Actor, error) {
+			return sweep.New(numSteps, params)
+		},
+		"LookAround": func(params motor.Params) (motor.Actor, error) {
+			return look_around.New(numSteps, params)
+		},
+		"Breathe": func(params motor.Params) (motor.Actor, error) {
+			return breathe.New(numSteps, params)
+		},
+		"DoubleTake": func(params motor.Params) (motor.Actor, error) {
+			return double_take.New(numSteps, params)
+		},
+	}
 
 	return &Handler{
-		controller:   controller,
-		limiter:      limiter,
-		logger:       logger,
-		actors:       actors,
-		sensor:       sensor,
-		motorCfg:     motorCfg,
-		magnetometer: magnetometer,
-		svgs:         svgs,
+		controller:      controller,
+		limiter:         limiter,
+		logger:          logger,
+		actors:          actors,
+		sensor:          sensor,
+		motorCfg:        motorCfg,
+		magnetometer:    magnetometer,
+		estimator:       estimator,
+		calibrationFile: calibrationFile,
+		svgs:            svgs,
 	}
 }
 
@@ -121,25 +151,176 @@ func (h *Handler) FindZero(ctx context.Context, empty *heads.Empty) (*heads.Empt
 		)
 	}
 
-	h.controller.SetActor(detector)
+	h.controller.FindZero(detector)
 
 	return &heads.Empty{}, nil
 }
 
+// CalibrateMagnet turns the head a full circle to sample the field at the current
+// temperature, and fits the calibration again with it
+func (h *Handler) CalibrateMagnet(ctx context.Context, empty *heads.Empty) (*heads.MagnetCalibration, error) {
+	if !h.magnetometer.HasHardware() {
+		return nil, status.Error(codes.FailedPrecondition, "no magnet sensor")
+	}
+
+	// steps are only positions once the head knows where zero is
+	zeroed := h.controller.GetState().Zeroed
+
+	type result struct {
+		turn *calibrator.Turn
+		err  error
+	}
+	done := make(chan result, 1)
+
+	h.controller.SetActor(calibrator.New(
+		h.magnetometer,
+		h.motorCfg.NumSteps,
+		h.motorCfg.DirectionChangePauses,
+		func(turn *calibrator.Turn, err error) {
+			done <- result{turn: turn, err: err}
+		},
+	))
+
+	var r result
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if r.err != nil {
+		return nil, status.Errorf(codes.Internal, "calibrate: %s", r.err)
+	}
+
+	cal := h.magnetometer.Calibration().Add(r.turn.Sample)
+	if zeroed {
+		fieldMap, err := magnetometer.NewFieldMap(h.motorCfg.NumSteps, r.turn.Sample.Temperature, r.turn.Readings)
+		if err != nil {
+			return nil, errors.Wrap(err, "field map")
+		}
+		cal.Map = fieldMap
+	}
+	h.magnetometer.SetCalibration(cal)
+	if h.calibrationFile != "" {
+		if err := cal.Save(h.calibrationFile); err != nil {
+			return nil, errors.Wrap(err, "save calibration")
+		}
+	}
+
+	svg, err := calibrator.Plot(cal)
+	if err != nil {
+		return nil, errors.Wrap(err, "plot")
+	}
+	h.svgs.Set("calibration.svg", svg)
+
+	h.logger.Info(
+		"calibrated magnet sensor",
+		zap.Float64("temperature", r.turn.Sample.Temperature),
+		zap.Int("samples", len(cal.Samples)),
+		zap.Bool("field_map", cal.Map != nil),
+	)
+
+	return &heads.MagnetCalibration{
+		Reference:   cal.Reference,
+		Offset:      []float64{cal.OffsetX, cal.OffsetY, cal.OffsetZ},
+		Coefficient: []float64{cal.CoefX, cal.CoefY, cal.CoefZ},
+		Samples:     int32(len(cal.Samples)),
+		FieldMap:    cal.Map != nil,
+		Plot:        svg,
+	}, nil
+}
+
 func (h *Handler) Status(ctx context.Context, empty *heads.Empty) (*heads.HeadState, error) {
 	return h.headState(), nil
 }
 
+var zeroStatus = map[motor.ZeroStatus]heads.ZeroStatus{
+	motor.ZeroUnknown:   heads.ZeroStatus_ZERO_UNKNOWN,
+	motor.ZeroSearching: heads.ZeroStatus_ZERO_SEARCHING,
+	motor.ZeroFound:     heads.ZeroStatus_ZERO_FOUND,
+	motor.ZeroFailed:    heads.ZeroStatus_ZERO_FAILED,
+}
+
+func (h *Handler) WatchState(in *heads.WatchStateIn, server heads.Head_WatchStateServer) error {
+	interval := 100 * time.Millisecond
+	if in.Interval != nil {
+		interval = in.Interval.AsDuration()
+	}
+	if interval < 10*time.Millisecond {
+		return status.Errorf(codes.InvalidArgument, "interval %s is too short", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var prev *motor.State
+	for {
+		state := h.controller.GetState()
+		if prev == nil || state.Changed(prev) {
+			if err := server.Send(h.headState()); err != nil {
+				return err
+			}
+			prev = state
+		}
+
+		select {
+		case <-ticker.C:
+		case <-server.Context().Done():
+			return nil
+		}
+	}
+}
+
 func (h *Handler) headState() *heads.HeadState {
 	state := h.controller.GetState()
+	degrees := 360 / float64(h.motorCfg.NumSteps)
 
-	return &heads.HeadState{
+	hs := &heads.HeadState{
 		Position:   int32(state.Pos),
 		Target:     int32(state.Target),
 		Rotation:   state.Rotation(),
 		Controller: state.ActorName,
 		StepsAway:  int32(state.StepsAway()),
 		Eta:        durationpb.New(state.Eta()),
+		Speed:      float64(state.Speed) * degrees,
+
+		Timeline:         state.Sequence,
+		TimelineProgress: state.Progress,
+
+		Energized:         state.Energized,
+		PositionUncertain: state.Uncertain,
+		Zeroed:            state.Zeroed,
+		ZeroStatus:        zeroStatus[state.ZeroStatus],
+		ZeroError:         state.ZeroError,
+	}
+
+	if state.Zeroed {
+		hs.ZeroedAt = timestamppb.New(state.ZeroedAt)
+	}
+
+	if est, at := h.estimator.Latest(); est != nil {
+		hs.Estimate = &heads.AngleEstimate{
+			Position:   int32(est.Pos),
+			Theta:      float64(est.Pos) * degrees,
+			Confidence: est.Confidence,
+			At:         timestamppb.New(at),
+		}
+	}
+
+	if state.Acceleration > 0 {
+		hs.Speed = state.MaxVelocity * degrees
+		hs.Acceleration = state.Acceleration * degrees
+		hs.Velocity = state.Velocity * degrees
+	}
+
+	return hs
+}
+
+// motion converts speeds from degrees to steps
+func (h *Handler) motion(speed, acceleration float64) motor.Motion {
+	steps := float64(h.motorCfg.NumSteps) / 360
+	return motor.Motion{
+		MaxVelocity:  speed * steps,
+		Acceleration: acceleration * steps,
 	}
 }
 
@@ -148,18 +329,65 @@ func (h *Handler) SetTarget(ctx context.Context, in *heads.SetTargetIn) (*heads.
 		h.logger.Debug("rotation", zap.Float64("theta", in.Theta))
 	})
 
+	h.controller.SetCommandMotion(h.motion(in.Speed, in.Acceleration))
 	h.controller.SetTargetRotation(in.Theta)
 
 	return h.headState(), nil
 }
 
 func (h *Handler) SetActor(ctx context.Context, in *heads.SetActorIn) (*heads.HeadState, error) {
-	actor := h.actors[in.Actor]
-	if actor == nil {
-		return nil, errors.New("invalid actor")
+	factory := h.actors[in.Actor]
+	if factory == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown actor %q", in.Actor)
+	}
+
+	actor, err := factory(in.Params)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %s", in.Actor, err)
+	}
+
+	h.controller.SetActorWithMotion(actor, h.motion(in.Speed, in.Acceleration))
+	return h.headState(), nil
+}
+
+func (h *Handler) RunTimeline(ctx context.Context, in *heads.RunTimelineIn) (*heads.HeadState, error) {
+	if err := in.Start.CheckValid(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "start: %s", err)
+	}
+
+	var keyframes []timeline.Keyframe
+	for i, kf := range in.Keyframes {
+		keyframe := timeline.Keyframe{
+			At:     kf.At.AsDuration(),
+			Theta:  kf.Theta,
+			Easing: kf.Easing,
+		}
+
+		if kf.Actor != "" {
+			factory := h.actors[kf.Actor]
+			if factory == nil {
+				return nil, status.Errorf(codes.InvalidArgument, "keyframe %d: unknown actor %q", i, kf.Actor)
+			}
+			actor, err := factory(kf.Params)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "keyframe %d: %s: %s", i, kf.Actor, err)
+			}
+			planned, ok := actor.(motor.Planned)
+			if !ok {
+				return nil, status.Errorf(codes.InvalidArgument, "keyframe %d: %s can't follow a timeline", i, kf.Actor)
+			}
+			keyframe.Actor = planned
+		}
+
+		keyframes = append(keyframes, keyframe)
+	}
+
+	tl, err := timeline.New(in.Name, h.motorCfg.NumSteps, in.Start.AsTime(), keyframes)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "timeline: %s", err)
 	}
 
-	h.controller.SetActor(actor)
+	h.controller.SetActorWithMotion(tl, motor.Motion{})
 	return h.headState(), nil
 }
 
@@ -1,6 +1,7 @@
 package head
 
 import (
+	"context"
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
 	"github.com/minor-industries/platform/common/broker"
@@ -8,25 +9,28 @@ import (
 	"github.com/minor-industries/platform/common/util"
 	"github.com/minor-industries/protobuf/gen/go/heads"
 	"github.com/minor-industries/theheads/head/cfg"
+	"github.com/minor-industries/theheads/head/fallback"
 	headgrpc "github.com/minor-industries/theheads/head/grpc"
 	"github.com/minor-industries/theheads/head/heartbeat"
 	"github.com/minor-industries/theheads/head/log_limiter"
 	"github.com/minor-industries/theheads/head/motor"
-	"github.com/minor-industries/theheads/head/motor/fake_stepper"
 	"github.com/minor-industries/theheads/head/motor/idle"
-	"github.com/minor-industries/theheads/head/motor/stepper"
+	"github.com/minor-industries/theheads/head/motor/sim"
 	"github.com/minor-industries/theheads/head/sensor"
 	"github.com/minor-industries/theheads/head/sensor/gpio_sensor"
 	"github.com/minor-industries/theheads/head/sensor/magnetometer"
-	"github.com/minor-industries/theheads/head/sensor/null_sensor"
+	"github.com/minor-industries/theheads/head/sensor/magnetometer/zero_detector"
 	"github.com/minor-industries/theheads/head/voices"
+	"github.com/minor-industries/theheads/schedule"
 	cmap "github.com/orcaman/concurrent-map/v2"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"math"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -43,11 +47,13 @@ func Run(env *cfg.Cfg) {
 	logger = logger.With(zap.String("instance", env.Instance))
 
 	var driver motor.Motor
+	var simulated *sim.Head
 
 	if env.FakeStepper {
-		driver = fake_stepper.NewMotor()
+		simulated = sim.New(env.Sim, env.Motor.NumSteps)
+		driver = simulated
 	} else {
-		driver, err = stepper.New(logger, env.Motor.MotorID)
+		driver, err = newDriver(logger, env)
 		if err != nil {
 			panic(err)
 		}
@@ -63,7 +69,7 @@ func Run(env *cfg.Cfg) {
 
 	var sensor sensor.Sensor
 	if env.FakeStepper {
-		sensor = null_sensor.Sensor{}
+		sensor = simulated.HallSensor()
 	} else {
 		s := gpio_sensor.New(env.SensorPin)
 		err := gpio_sensor.Initialize(s)
@@ -73,15 +79,21 @@ func Run(env *cfg.Cfg) {
 		sensor = s
 	}
 
-	mm, err := magnetometer.Setup(
-		logger,
-		env.I2CBus,
-		env.EnableMagnetSensor,
-		env.MagnetSensorAddrs,
-	)
-	if err != nil {
-		panic(err)
+	var raw magnetometer.Sensor
+	if env.FakeStepper {
+		raw = simulated.Magnetometer(env.EnableMagnetSensor)
+	} else {
+		raw, err = magnetometer.Setup(
+			logger,
+			env.I2CBus,
+			env.EnableMagnetSensor,
+			env.MagnetSensorAddrs,
+		)
+		if err != nil {
+			panic(err)
+		}
 	}
+	mm := magnetometer.NewCompensated(raw, loadCalibration(logger, env.MagnetCalibrationFile))
 
 	// hack: use sync.Once to allow multiple instances in-process
 	metricsOnce.Do(func() {
@@ -97,11 +109,68 @@ func Run(env *cfg.Cfg) {
 		idle.New(),
 	)
 
+	// with a state file, SIGTERM and SIGINT make Run save the state once more and return
+	// rather than killing the head outright
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stateSaved := make(chan struct{})
+
+	if env.StateFile != "" {
+		restoreState(logger, env.StateFile, env.StateMaxAge, controller)
+
+		ctx, cancel = signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+		defer cancel()
+		go func() {
+			keepState(ctx, logger, env.StateFile, env.StateSaveInterval, controller)
+			close(stateSaved)
+		}()
+	} else {
+		close(stateSaved)
+	}
+
+	if mm.HasHardware() && !controller.GetState().Zeroed {
+		estimateState(logger, mm, env.EstimateConfidence, controller)
+	}
+
 	go controller.Run()
 
+	if mm.HasHardware() {
+		go zero_detector.NewMonitor(
+			logger,
+			mm,
+			controller,
+			b,
+			env.Instance,
+			env.Motor.NumSteps,
+			env.DriftWindow,
+			env.DriftTolerance,
+			env.DriftRezero,
+		).Run()
+	}
+
+	estimator := magnetometer.NewEstimator(logger, mm, env.EstimateInterval)
+	if mm.HasHardware() {
+		go estimator.Run()
+	}
+
 	heartbeatMonitor := heartbeat.NewMonitor(logger, env, b, hHeartbeatDuration)
 	go heartbeatMonitor.PublishLoop()
 
+	voicesServer := voices.NewServer(&env.Voices, logger)
+
+	go fallback.New(
+		logger,
+		&env.Fallback,
+		env.Instance,
+		env.Motor.NumSteps,
+		heartbeatMonitor,
+		controller,
+		func(ctx context.Context) error {
+			_, err := voicesServer.Random(ctx, &heads.Empty{})
+			return err
+		},
+	).Run()
+
 	svgs := cmap.New[[]byte]()
 
 	h := headgrpc.NewHandler(
@@ -110,6 +179,8 @@ func Run(env *cfg.Cfg) {
 		logger,
 		sensor,
 		mm,
+		estimator,
+		env.MagnetCalibrationFile,
 		&env.Motor,
 		svgs,
 	)
@@ -119,10 +190,13 @@ func Run(env *cfg.Cfg) {
 		Port:   env.Port,
 		GrpcSetup: func(grpcServer *grpc.Server) error {
 			heads.RegisterHeadServer(grpcServer, h)
-			heads.RegisterVoicesServer(grpcServer, voices.NewServer(&env.Voices, logger))
+			heads.RegisterVoicesServer(grpcServer, voicesServer)
 			heads.RegisterEventsServer(grpcServer, h)
 			heads.RegisterPingServer(grpcServer, h)
 			heads.RegisterHeartbeatServer(grpcServer, heartbeatMonitor)
+			heads.RegisterSchedulerServer(grpcServer, newScheduler(
+				schedule.New(logger, prometheus.DefaultRegisterer, schedule.Loopback(env.Port)),
+			))
 			return nil
 		},
 		HttpSetup: func(router *gin.Engine) error {
@@ -136,6 +210,8 @@ func Run(env *cfg.Cfg) {
 				}
 				c.Data(200, "image/svg+xml", svg)
 			})
+
+			setupStatusPage(logger, router, env.Instance, h, voicesServer, svgs)
 			return nil
 		},
 	})
@@ -143,10 +219,35 @@ func Run(env *cfg.Cfg) {
 		panic(err)
 	}
 
-	err = s.Run()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Run()
+	}()
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			panic(err)
+		}
+	case <-ctx.Done():
+		logger.Info("stopping")
+	}
+
+	cancel()
+	<-stateSaved
+}
+
+// loadCalibration leaves the readings uncompensated until the sensor has been calibrated
+func loadCalibration(logger *zap.Logger, filename string) *magnetometer.Calibration {
+	if filename == "" {
+		return nil
+	}
+	cal, err := magnetometer.LoadCalibration(filename)
 	if err != nil {
-		panic(err)
+		logger.Info("no magnet sensor calibration", zap.Error(err))
+		return nil
 	}
+	return cal
 }
 
 var (
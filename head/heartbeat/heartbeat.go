@@ -26,6 +26,7 @@ type Monitor struct {
 
 	lock        sync.Mutex
 	currentBeat beat
+	lastAck     time.Time
 
 	hDuration prometheus.Histogram
 }
@@ -51,9 +52,18 @@ func NewMonitor(
 		cfg:       cfg,
 		broker:    b,
 		hDuration: hDuration,
+		lastAck:   time.Now(), // give boss a chance before counting it as gone
 	}
 }
 
+// LastAck is when boss last acked a heartbeat
+func (m *Monitor) LastAck() time.Time {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.lastAck
+}
+
 func (m *Monitor) PublishLoop() {
 	ticker := time.NewTicker(m.cfg.HeartbeatInterval)
 
@@ -101,5 +111,6 @@ func (m *Monitor) ack(id string) {
 		)
 		m.hDuration.Observe(dt.Seconds())
 		m.currentBeat = beat{}
+		m.lastAck = time.Now()
 	}
 }
@@ -0,0 +1,72 @@
+package motor_test
+
+import (
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/breathe"
+	"github.com/minor-industries/theheads/head/motor/double_take"
+	"github.com/minor-industries/theheads/head/motor/fake_stepper"
+	"github.com/minor-industries/theheads/head/motor/jitter"
+	"github.com/minor-industries/theheads/head/motor/look_around"
+	"github.com/minor-industries/theheads/head/motor/sweep"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParams(t *testing.T) {
+	_, err := jitter.New(200, motor.Params{"amplitude": 5, "amplitdue": 5})
+	assert.EqualError(t, err, "unknown params [amplitdue], expected some of [amplitude]")
+
+	_, err = sweep.New(200, motor.Params{"from": 10})
+	assert.Error(t, err)
+
+	_, err = look_around.New(200, motor.Params{"min_pause": 5, "max_pause": 1})
+	assert.Error(t, err)
+}
+
+func TestSweepAndBreathe(t *testing.T) {
+	s, err := sweep.New(200, motor.Params{"from": 350, "to": 10, "period": 1000})
+	require.NoError(t, err)
+	assert.Equal(t, 194, s.Goal(0, 100)) // starts at from, ignoring the target
+
+	s, err = sweep.New(200, motor.Params{"width": 90})
+	require.NoError(t, err)
+	assert.Equal(t, 75, s.Goal(0, 100)) // centered on the target
+
+	b, err := breathe.New(200, motor.Params{"amplitude": 9})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		assert.InDelta(t, 100, b.Goal(0, 100), 5)
+	}
+}
+
+func TestLookAround(t *testing.T) {
+	l, err := look_around.New(200, motor.Params{"range": 36, "min_pause": 10, "max_pause": 10})
+	require.NoError(t, err)
+
+	goal := l.Goal(0, 100)
+	assert.InDelta(t, 100, goal, 10)
+	assert.Equal(t, goal, l.Goal(0, 100), "holds the glance during the pause")
+	assert.Equal(t, goal+20, l.Goal(0, 120), "glances are relative to the target")
+}
+
+func TestDoubleTake(t *testing.T) {
+	d, err := double_take.New(200, motor.Params{"glance": 0.05, "pause": 0.05})
+	require.NoError(t, err)
+
+	assert.Equal(t, 100, d.Goal(20, 100))
+	assert.Equal(t, motor.Motion{}, d.Motion())
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, 20, d.Goal(60, 100), "looks back where it started")
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 100, d.Goal(30, 100))
+	assert.Greater(t, d.Motion().MaxVelocity, 500.0)
+
+	// and the controller snaps round, up to its limits
+	c := newController(fake_stepper.NewMotor(), d)
+	c.Tick()
+	assert.Equal(t, 100.0, c.GetState().MaxVelocity)
+}
@@ -0,0 +1,45 @@
+package breathe
+
+import (
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/seeker"
+	"github.com/pkg/errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Breathe sways gently around the target, so a head holding still doesn't look frozen
+type Breathe struct {
+	*seeker.Seeker
+	t0        time.Time
+	amplitude float64 // steps
+	period    time.Duration
+}
+
+func New(numSteps int, params motor.Params) (*Breathe, error) {
+	if err := params.Check("amplitude", "period"); err != nil {
+		return nil, err
+	}
+
+	period := params.Duration("period", 5*time.Second)
+	if period <= 0 {
+		return nil, errors.New("period must be positive")
+	}
+
+	return &Breathe{
+		Seeker:    seeker.New(numSteps),
+		t0:        time.Now().Add(-time.Duration(rand.Int63n(int64(period)))),
+		amplitude: params.Float("amplitude", 4) / 360 * float64(numSteps),
+		period:    period,
+	}, nil
+}
+
+func (b *Breathe) Name() string {
+	return "Breathe"
+}
+
+func (b *Breathe) Goal(pos, target int) int {
+	t := time.Since(b.t0).Seconds() / b.period.Seconds()
+	return target + int(math.Round(b.amplitude*math.Sin(2*math.Pi*t)))
+}
@@ -11,11 +11,43 @@ import (
 )
 
 type State struct {
-	Pos       int
-	Target    int
-	Speed     int
-	Steps     int
-	ActorName string
+	Pos        int
+	Target     int
+	Speed      int
+	Steps      int
+	ActorName  string
+	Zeroed     bool
+	ZeroedAt   time.Time
+	ZeroStatus ZeroStatus
+	ZeroError  string
+	Energized  bool
+	Released   bool // the coils were turned off, rather than not used yet
+
+	// the head may have been turned while the motor was off, so it needs zeroing again
+	Uncertain bool
+
+	// set while a Planned actor is in control
+	Velocity     float64
+	MaxVelocity  float64
+	Acceleration float64
+
+	// set while a Sequenced actor is in control
+	Sequence string
+	Progress float64
+}
+
+// Changed says whether anything worth telling a watcher about differs from prev. The
+// velocity and progress through a timeline change all the time, so they don't count.
+func (s *State) Changed(prev *State) bool {
+	return s.Pos != prev.Pos ||
+		s.Target != prev.Target ||
+		s.ActorName != prev.ActorName ||
+		s.Zeroed != prev.Zeroed ||
+		s.ZeroStatus != prev.ZeroStatus ||
+		s.ZeroError != prev.ZeroError ||
+		s.Energized != prev.Energized ||
+		s.Uncertain != prev.Uncertain ||
+		s.Sequence != prev.Sequence
 }
 
 func (s *State) TargetRotation() float64 {
@@ -36,9 +68,22 @@ func (s *State) StepsAway() int {
 	return fwd
 }
 
+// StepsTo is the shortest way from Pos to Target, negative for backwards
+func (s *State) StepsTo() int {
+	fwd := Mod(s.Target-s.Pos, s.Steps)
+	bck := Mod(s.Pos-s.Target, s.Steps)
+
+	if fwd < bck {
+		return fwd
+	}
+	return -bck
+}
+
 func (s *State) Eta() time.Duration {
+	if s.Acceleration > 0 {
+		return profileTime(s.Velocity, float64(s.StepsTo()), s.MaxVelocity, s.Acceleration)
+	}
 	return time.Duration(float64(s.StepsAway()) / float64(s.Speed) * float64(time.Second))
-
 }
 
 type Actor interface {
@@ -47,6 +92,26 @@ type Actor interface {
 	Finish(controller *Controller)
 }
 
+// Planned actors only say where they want the head to be, and leave the controller to
+// get there smoothly, accelerating up to MaxVelocity and slowing down before the goal.
+// Act isn't called for them.
+type Planned interface {
+	Actor
+	Goal(pos, target int) int
+}
+
+// Paced actors change the speed of their planned moves as they go, e.g. to snap around.
+// Motion given with SetActor or a command still takes precedence.
+type Paced interface {
+	Motion() Motion
+}
+
+// Sequenced actors play through a fixed sequence, such as a timeline, and report how far
+// along it they are from 0 to 1
+type Sequenced interface {
+	Sequence() (name string, progress float64)
+}
+
 func hasStep(steps []Direction, direction Direction) bool {
 	for _, d := range steps {
 		if d == direction {
@@ -56,11 +121,49 @@ func hasStep(steps []Direction, direction Direction) bool {
 	return false
 }
 
+type ZeroStatus int
+
+const (
+	ZeroUnknown ZeroStatus = iota
+	ZeroSearching
+	ZeroFound
+	ZeroFailed
+)
+
 type Cfg struct {
 	MotorID               int `envconfig:"default=0"`
 	NumSteps              int `envconfig:"default=200"`
 	StepSpeed             int `envconfig:"default=30"`
 	DirectionChangePauses int `envconfig:"default=10"`
+
+	// for Planned actors, in steps per second and steps per second squared. The defaults
+	// can be changed per actor or per command, up to the limits.
+	MaxVelocity       float64 `envconfig:"default=45"`
+	Acceleration      float64 `envconfig:"default=90"`
+	VelocityLimit     float64 `envconfig:"default=120"`
+	AccelerationLimit float64 `envconfig:"default=400"`
+
+	// the coils are released once the head has held still this long, zero to keep them on.
+	// ReleaseSlips says a released head can be turned, e.g. by the wind.
+	ReleaseAfter time.Duration `envconfig:"default=2m"`
+	ReleaseSlips bool          `envconfig:"default=true"`
+}
+
+// Motion overrides the speed and acceleration of planned moves, in steps per second and
+// steps per second squared. Zero leaves the setting alone.
+type Motion struct {
+	MaxVelocity  float64
+	Acceleration float64
+}
+
+func (m Motion) or(fallback Motion) Motion {
+	if m.MaxVelocity <= 0 {
+		m.MaxVelocity = fallback.MaxVelocity
+	}
+	if m.Acceleration <= 0 {
+		m.Acceleration = fallback.Acceleration
+	}
+	return m
 }
 
 type Controller struct {
@@ -72,13 +175,31 @@ type Controller struct {
 
 	numSteps int
 
-	pos    int
-	target int
+	pos        int
+	target     int
+	zeroed     bool
+	zeroedAt   time.Time
+	zeroStatus ZeroStatus
+	zeroError  string
+
+	energized    bool
+	released     bool
+	idleFor      time.Duration
+	releaseAfter time.Duration
+	releaseSlips bool
+	uncertain    bool
 
 	speed int // steps per second
 	delay time.Duration
 
 	prevSteps []Direction
+	planner   *planner
+
+	defaultMotion Motion
+	limits        Motion
+	actorMotion   Motion
+	pacedMotion   Motion
+	commandMotion Motion
 
 	name string
 
@@ -97,6 +218,15 @@ func NewController(
 	if cfg.StepSpeed == 0 {
 		panic("speed can't be 0")
 	}
+	if cfg.MaxVelocity <= 0 || cfg.Acceleration <= 0 {
+		panic("max velocity and acceleration must be positive")
+	}
+	if cfg.ReleaseAfter < 0 {
+		panic("release after can't be negative")
+	}
+	if cfg.VelocityLimit < cfg.MaxVelocity || cfg.AccelerationLimit < cfg.Acceleration {
+		panic("motion limits can't be below the defaults")
+	}
 
 	var prevSteps []Direction
 	for i := 0; i < cfg.DirectionChangePauses; i++ {
@@ -112,7 +242,17 @@ func NewController(
 		delay:    time.Duration(float64(time.Second) / float64(cfg.StepSpeed)),
 		name:     name,
 
+		releaseAfter: cfg.ReleaseAfter,
+		releaseSlips: cfg.ReleaseSlips,
+
 		prevSteps: prevSteps,
+		planner: &planner{
+			maxVelocity:  cfg.MaxVelocity,
+			acceleration: cfg.Acceleration,
+			idle:         time.Duration(float64(time.Second) / float64(cfg.StepSpeed)),
+		},
+		defaultMotion: Motion{MaxVelocity: cfg.MaxVelocity, Acceleration: cfg.Acceleration},
+		limits:        Motion{MaxVelocity: cfg.VelocityLimit, Acceleration: cfg.AccelerationLimit},
 
 		defaultActor: defaultActor,
 		actor:        defaultActor,
@@ -130,14 +270,88 @@ func (s *Controller) Step(direction Direction) error {
 			direction = NoStep
 		}
 
-		s.prevSteps = append(s.prevSteps[1:], direction)
+		s.remember(direction)
 		s.pos += int(direction)
+		s.stepped(direction)
 	}()
 
 	err := s.motor.Step(direction)
 	return errors.Wrap(err, "step")
 }
 
+// move steps without the direction change guard, for planned moves which have already
+// slowed to a stop before turning around
+func (s *Controller) move(direction Direction) error {
+	s.lock.Lock()
+	s.remember(direction)
+	s.pos += int(direction)
+	s.stepped(direction)
+	s.lock.Unlock()
+
+	err := s.motor.Step(direction)
+	return errors.Wrap(err, "step")
+}
+
+// remember keeps the last few steps for the direction change guard. Needs the lock.
+func (s *Controller) remember(direction Direction) {
+	if len(s.prevSteps) == 0 {
+		return // no pauses configured
+	}
+	s.prevSteps = append(s.prevSteps[1:], direction)
+}
+
+// stepped notes that the motor is, or is about to be, energized again. Needs the lock.
+func (s *Controller) stepped(direction Direction) {
+	if direction == NoStep {
+		return
+	}
+	s.idleFor = 0
+	s.released = false
+	if !s.energized {
+		s.energized = true
+		gEnergized.Set(1)
+	}
+}
+
+// idle accounts for a tick, and releases the coils once the head has held still for
+// long enough. The next step energizes them again.
+func (s *Controller) idle(delay time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.energized {
+		return
+	}
+
+	cEnergizedSeconds.Add(delay.Seconds())
+	s.idleFor += delay
+	if s.releaseAfter == 0 || s.idleFor < s.releaseAfter {
+		return
+	}
+
+	if err := s.release(); err != nil {
+		s.logger.Error("error releasing motor", zap.Error(err))
+		return
+	}
+	s.logger.Debug("released idle motor", zap.Bool("uncertain", s.uncertain))
+}
+
+// release turns the coils off. Needs the lock.
+func (s *Controller) release() error {
+	if err := s.motor.Off(); err != nil {
+		return err
+	}
+
+	s.energized = false
+	s.released = true
+	gEnergized.Set(0)
+	if s.releaseSlips && !s.uncertain {
+		s.uncertain = true
+		gPositionUncertain.Set(1)
+	}
+	return nil
+}
+
 func (s *Controller) getActor() Actor {
 	s.lock.Lock()
 	s.lock.Unlock()
@@ -167,13 +381,33 @@ func (s *Controller) GetState() *State {
 }
 
 func (s *Controller) state() *State {
-	return &State{
+	state := &State{
 		Pos:       s.pos,
 		Target:    s.target,
 		Speed:     s.speed,
 		Steps:     s.numSteps,
 		ActorName: s.actor.Name(),
+		Zeroed:    s.zeroed,
+		ZeroedAt:  s.zeroedAt,
+
+		ZeroStatus: s.zeroStatus,
+		ZeroError:  s.zeroError,
+		Energized:  s.energized,
+		Released:   s.released,
+		Uncertain:  s.uncertain,
+	}
+
+	if _, ok := s.actor.(Planned); ok {
+		state.Velocity = s.planner.velocity
+		state.MaxVelocity = s.planner.maxVelocity
+		state.Acceleration = s.planner.acceleration
 	}
+
+	if seq, ok := s.actor.(Sequenced); ok {
+		state.Sequence, state.Progress = seq.Sequence()
+	}
+
+	return state
 }
 
 func Mod(x, y int) int {
@@ -205,31 +439,111 @@ func (s *Controller) publish() {
 }
 
 func (s *Controller) Run() {
-	delay := float64(time.Second) / float64(s.Speed())
-	ticker := time.NewTicker(time.Duration(delay))
-
-	for range ticker.C {
-		step, done := s.Control()
-		if done {
-			s.getActor().Finish(s)
-			s.SetActor(s.defaultActor)
+	next := time.Now()
+	for {
+		next = next.Add(s.Tick())
+		if wait := time.Until(next); wait > 0 {
+			time.Sleep(wait)
+		} else {
+			next = time.Now() // fell behind, don't try to catch up
 		}
-		err := s.Step(step)
-		if err != nil {
-			s.logger.Error("error stepping", zap.Error(err))
-			continue
+	}
+}
+
+// Tick takes one step, and returns how long to wait before the next one
+func (s *Controller) Tick() time.Duration {
+	delay := s.tick()
+	s.idle(delay)
+	return delay
+}
+
+func (s *Controller) tick() time.Duration {
+	actor := s.getActor()
+
+	if planned, ok := actor.(Planned); ok {
+		s.lock.Lock()
+		pos, target := s.pos, s.target
+		goal := planned.Goal(pos, target)
+		if paced, ok := actor.(Paced); ok {
+			s.pacedMotion = paced.Motion()
+			s.applyMotion()
 		}
+		dist := (&State{Pos: pos, Target: goal, Steps: s.numSteps}).StepsTo()
+		step, delay := s.planner.next(dist)
+		s.lock.Unlock()
 
 		if step != NoStep {
-			s.publish()
+			if err := s.move(step); err != nil {
+				s.logger.Error("error stepping", zap.Error(err))
+			} else {
+				s.publish()
+			}
 		}
+		return delay
 	}
+
+	s.lock.Lock()
+	s.planner.stop()
+	s.lock.Unlock()
+
+	step, done := s.Control()
+	if done {
+		s.getActor().Finish(s)
+		s.SetActor(s.defaultActor)
+	}
+	err := s.Step(step)
+	if err != nil {
+		s.logger.Error("error stepping", zap.Error(err))
+		return s.delay
+	}
+
+	if step != NoStep {
+		s.publish()
+	}
+	return s.delay
 }
 
 func (s *Controller) SetActor(actor Actor) {
+	s.SetActorWithMotion(actor, Motion{})
+}
+
+// ResetActor goes back to the actor the head starts with
+func (s *Controller) ResetActor() {
+	s.SetActor(s.defaultActor)
+}
+
+// SetActorWithMotion also sets how fast the actor moves the head, until the next actor.
+// Any motion from earlier commands is dropped.
+func (s *Controller) SetActorWithMotion(actor Actor, motion Motion) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
+
+	if s.zeroStatus == ZeroSearching && actor != s.actor {
+		s.zeroStatus = ZeroFailed
+		s.zeroError = "interrupted by " + actor.Name()
+	}
+
 	s.actor = actor
+	s.actorMotion = motion
+	s.pacedMotion = Motion{}
+	s.commandMotion = Motion{}
+	s.applyMotion()
+}
+
+// SetCommandMotion overrides the actor's motion, e.g. along with a new target. Zero
+// values go back to the actor's.
+func (s *Controller) SetCommandMotion(motion Motion) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.commandMotion = motion
+	s.applyMotion()
+}
+
+func (s *Controller) applyMotion() {
+	m := s.commandMotion.or(s.actorMotion).or(s.pacedMotion).or(s.defaultMotion)
+	s.planner.maxVelocity = math.Max(1, math.Min(m.MaxVelocity, s.limits.MaxVelocity))
+	s.planner.acceleration = math.Max(1, math.Min(m.Acceleration, s.limits.Acceleration))
 }
 
 func (s *Controller) SetCurrentPositionAsZero() {
@@ -238,12 +552,64 @@ func (s *Controller) SetCurrentPositionAsZero() {
 
 	s.pos = 0
 	s.target = 0
+	s.zeroed = true
+	s.zeroedAt = time.Now()
+	s.zeroStatus = ZeroFound
+	s.zeroError = ""
+	s.uncertain = false
+	gPositionUncertain.Set(0)
+}
+
+// FindZero hands over to a zero detector, which calls SetCurrentPositionAsZero or
+// ZeroFailed when it's done
+func (s *Controller) FindZero(detector Actor) {
+	s.SetActor(detector)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.zeroStatus = ZeroSearching
+	s.zeroError = ""
+}
+
+// ZeroFailed leaves the position and any earlier zero alone
+func (s *Controller) ZeroFailed(reason string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.logger.Warn("zero detection failed", zap.String("reason", reason))
+	s.zeroStatus = ZeroFailed
+	s.zeroError = reason
+}
+
+// Restore picks up where an earlier run of the head left off. A zero time means the
+// head isn't known to be zeroed, e.g. because the saved state was stale.
+func (s *Controller) Restore(pos int, zeroedAt time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.pos = pos
+	s.target = pos
+	s.zeroed = !zeroedAt.IsZero()
+	s.zeroedAt = zeroedAt
+	if s.zeroed {
+		s.zeroStatus = ZeroFound
+	}
+}
+
+// ShiftZero moves zero by steps, for when the head finds it has drifted from where it
+// thinks it is. The target stays put.
+func (s *Controller) ShiftZero(steps int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.pos -= steps
 }
 
 func (s *Controller) TurnOffMotor() error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	err := s.motor.Off()
+	err := s.release()
 	return errors.Wrap(err, "motor off")
 }
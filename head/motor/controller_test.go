@@ -1,29 +1,47 @@
 package motor_test
 
 import (
+	"github.com/minor-industries/platform/common/broker"
 	"github.com/minor-industries/theheads/head/motor"
 	"github.com/minor-industries/theheads/head/motor/fake_stepper"
+	"github.com/minor-industries/theheads/head/motor/idle"
+	"github.com/minor-industries/theheads/head/motor/seeker"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"testing"
+	"time"
 )
 
+func newController(m motor.Motor, actor motor.Actor, opts ...func(cfg *motor.Cfg)) *motor.Controller {
+	b := broker.NewBroker()
+	go b.Start()
+
+	cfg := &motor.Cfg{
+		NumSteps:              200,
+		StepSpeed:             30,
+		DirectionChangePauses: 3,
+		MaxVelocity:           40,
+		Acceleration:          80,
+		VelocityLimit:         100,
+		AccelerationLimit:     200,
+		ReleaseAfter:          5 * time.Second,
+		ReleaseSlips:          true,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return motor.NewController(zap.NewNop(), m, b, cfg, "head-03", actor)
+}
+
 func TestController_Step(t *testing.T) {
-	c := motor.NewController(
-		nil,
-		fake_stepper.NewMotor(),
-		nil,
-		200,
-		30,
-		3,
-		"head-03",
-		nil,
-	)
+	c := newController(fake_stepper.NewMotor(), idle.New())
 
 	stepAndCheck := func(direction motor.Direction, expected int) {
 		err := c.Step(direction)
 		assert.NoError(t, err)
-		pos, _ := c.State()
-		assert.Equal(t, pos, expected)
+		assert.Equal(t, expected, c.GetState().Pos)
 	}
 
 	stepAndCheck(motor.Forward, 1)
@@ -47,3 +65,157 @@ func TestController_Step(t *testing.T) {
 
 	stepAndCheck(motor.Backward, 0)
 }
+
+func TestController_NoDirectionChangePauses(t *testing.T) {
+	c := newController(fake_stepper.NewMotor(), idle.New(), func(cfg *motor.Cfg) {
+		cfg.DirectionChangePauses = 0
+	})
+
+	// turning around right away is allowed
+	require.NoError(t, c.Step(motor.Forward))
+	require.NoError(t, c.Step(motor.Backward))
+	require.NoError(t, c.Step(motor.Backward))
+	assert.Equal(t, -1, c.GetState().Pos)
+}
+
+// run ticks the controller in simulated time until the head settles on its target
+func run(t *testing.T, c *motor.Controller, limit time.Duration, each func(elapsed time.Duration)) time.Duration {
+	var elapsed time.Duration
+	for elapsed < limit {
+		elapsed += c.Tick()
+		if each != nil {
+			each(elapsed)
+		}
+		state := c.GetState()
+		if state.Pos == state.Target && state.Velocity == 0 {
+			return elapsed
+		}
+	}
+	t.Fatal("head didn't settle")
+	return 0
+}
+
+func TestController_Planned(t *testing.T) {
+	m := fake_stepper.NewMotor()
+	c := newController(m, seeker.New(200))
+
+	c.SetTargetRotation(90) // 50 steps
+	eta := c.GetState().Eta()
+
+	var prev float64
+	elapsed := run(t, c, 10*time.Second, func(time.Duration) {
+		v := c.GetState().Velocity
+		assert.LessOrEqual(t, v, 40.0)
+		assert.LessOrEqual(t, v-prev, 13.0) // about sqrt(2*80), the first step from rest
+		prev = v
+	})
+
+	assert.Equal(t, 50, m.Pos)
+	assert.InDelta(t, eta.Seconds(), elapsed.Seconds(), 0.1)
+
+	// turn around mid-move: the head slows down and overshoots rather than reversing at speed
+	c.SetTargetRotation(180)
+	for c.GetState().Velocity < 30 {
+		c.Tick()
+	}
+	c.SetTargetRotation(0)
+	reversedAt := m.Pos
+
+	var furthest int
+	run(t, c, 10*time.Second, func(time.Duration) {
+		if m.Pos > furthest {
+			furthest = m.Pos
+		}
+	})
+
+	require.Equal(t, 0, c.GetState().Pos)
+	assert.Equal(t, 0, m.Pos)
+	assert.Greater(t, furthest, reversedAt)
+}
+
+func TestController_Motion(t *testing.T) {
+	c := newController(fake_stepper.NewMotor(), seeker.New(200))
+	motion := func() (float64, float64) {
+		state := c.GetState()
+		return state.MaxVelocity, state.Acceleration
+	}
+
+	v, a := motion()
+	assert.Equal(t, 40.0, v)
+	assert.Equal(t, 80.0, a)
+
+	// per actor, then per command on top, clamped to the limits
+	c.SetActorWithMotion(seeker.New(200), motor.Motion{MaxVelocity: 10})
+	v, a = motion()
+	assert.Equal(t, 10.0, v)
+	assert.Equal(t, 80.0, a)
+
+	c.SetCommandMotion(motor.Motion{MaxVelocity: 500, Acceleration: 150})
+	v, a = motion()
+	assert.Equal(t, 100.0, v)
+	assert.Equal(t, 150.0, a)
+
+	c.SetCommandMotion(motor.Motion{})
+	v, _ = motion()
+	assert.Equal(t, 10.0, v)
+
+	c.SetActor(seeker.New(200))
+	v, _ = motion()
+	assert.Equal(t, 40.0, v)
+
+	c.SetActor(idle.New())
+	assert.Equal(t, 0.0, c.GetState().Acceleration)
+}
+
+func TestController_Release(t *testing.T) {
+	m := fake_stepper.NewMotor()
+	c := newController(m, seeker.New(200))
+
+	c.SetTargetRotation(90)
+	run(t, c, 10*time.Second, nil)
+	assert.True(t, m.Energized)
+	assert.True(t, c.GetState().Energized)
+
+	// holding still, until the coils are released
+	var held time.Duration
+	for m.Energized {
+		held += c.Tick()
+		require.Less(t, held, 10*time.Second)
+	}
+	assert.InDelta(t, 5.0, held.Seconds(), 0.2)
+
+	state := c.GetState()
+	assert.False(t, state.Energized)
+	assert.True(t, state.Uncertain)
+
+	// the next move energizes them again, but only zeroing makes the position certain
+	c.SetTargetRotation(0)
+	run(t, c, 10*time.Second, nil)
+	assert.True(t, m.Energized)
+	assert.True(t, c.GetState().Uncertain)
+
+	c.SetCurrentPositionAsZero()
+	assert.False(t, c.GetState().Uncertain)
+}
+
+func TestController_Zero(t *testing.T) {
+	c := newController(fake_stepper.NewMotor(), idle.New())
+	assert.Equal(t, motor.ZeroUnknown, c.GetState().ZeroStatus)
+
+	c.FindZero(seeker.New(200))
+	before := c.GetState()
+	assert.Equal(t, motor.ZeroSearching, before.ZeroStatus)
+
+	c.SetActor(idle.New())
+	state := c.GetState()
+	assert.Equal(t, motor.ZeroFailed, state.ZeroStatus)
+	assert.Equal(t, "interrupted by Idle", state.ZeroError)
+	assert.True(t, state.Changed(before))
+
+	c.FindZero(seeker.New(200))
+	c.SetCurrentPositionAsZero()
+	state = c.GetState()
+	assert.Equal(t, motor.ZeroFound, state.ZeroStatus)
+	assert.Empty(t, state.ZeroError)
+	assert.False(t, c.GetState().Changed(state))
+}
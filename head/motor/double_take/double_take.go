@@ -0,0 +1,81 @@
+package double_take
+
+import (
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/seeker"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// DoubleTake glances at the target, looks back where it was as if it hadn't noticed,
+// then snaps round to stare at the target
+type DoubleTake struct {
+	*seeker.Seeker
+	glance time.Duration
+	pause  time.Duration
+	snap   motor.Motion
+
+	started bool
+	t0      time.Time
+	start   int
+}
+
+func New(numSteps int, params motor.Params) (*DoubleTake, error) {
+	if err := params.Check("glance", "pause", "snap_speed", "snap_acceleration"); err != nil {
+		return nil, err
+	}
+
+	steps := float64(numSteps) / 360
+	d := &DoubleTake{
+		Seeker: seeker.New(numSteps),
+		glance: params.Duration("glance", 600*time.Millisecond),
+		pause:  params.Duration("pause", 800*time.Millisecond),
+		snap: motor.Motion{
+			// as fast as the head allows, unless told otherwise
+			MaxVelocity:  params.Float("snap_speed", 1000) * steps,
+			Acceleration: params.Float("snap_acceleration", 5000) * steps,
+		},
+	}
+
+	if d.glance < 0 || d.pause < 0 {
+		return nil, errors.New("glance and pause can't be negative")
+	}
+
+	return d, nil
+}
+
+func (d *DoubleTake) Name() string {
+	return "DoubleTake"
+}
+
+func (d *DoubleTake) Goal(pos, target int) int {
+	if !d.started {
+		d.started = true
+		d.t0 = time.Now()
+		d.start = pos
+	}
+
+	if d.looking() == "away" {
+		return d.start
+	}
+	return target
+}
+
+func (d *DoubleTake) looking() string {
+	elapsed := time.Since(d.t0)
+	switch {
+	case elapsed < d.glance:
+		return "glance"
+	case elapsed < d.glance+d.pause:
+		return "away"
+	default:
+		return "stare"
+	}
+}
+
+func (d *DoubleTake) Motion() motor.Motion {
+	if d.started && d.looking() == "stare" {
+		return d.snap
+	}
+	return motor.Motion{}
+}
@@ -5,6 +5,8 @@ import (
 )
 
 type Motor struct {
+	Pos       int
+	Energized bool
 }
 
 func NewMotor() *Motor {
@@ -12,6 +14,10 @@ func NewMotor() *Motor {
 }
 
 func (m *Motor) Step(direction motor.Direction) error {
+	if direction != motor.NoStep {
+		m.Pos += int(direction)
+		m.Energized = true
+	}
 	return nil
 }
 
@@ -20,5 +26,6 @@ func (m *Motor) Start() error {
 }
 
 func (m *Motor) Off() error {
+	m.Energized = false
 	return nil
 }
@@ -9,23 +9,36 @@ import (
 )
 
 type Actor struct {
-	seeker *seeker.Seeker
-	t0     time.Time
+	seeker    *seeker.Seeker
+	t0        time.Time
+	amplitude float64 // steps
 }
 
-func New(numSteps int) *Actor {
+// New takes the amplitude param, in degrees
+func New(numSteps int, params motor.Params) (*Actor, error) {
+	if err := params.Check("amplitude"); err != nil {
+		return nil, err
+	}
+
 	offset := time.Duration(1000*rand.Float64()) * time.Second
 	return &Actor{
-		seeker: seeker.New(numSteps),
-		t0:     time.Now().Add(offset),
-	}
+		seeker:    seeker.New(numSteps),
+		t0:        time.Now().Add(offset),
+		amplitude: float64(params.Steps("amplitude", 18, numSteps)),
+	}, nil
 }
 
-func (a Actor) Act(pos, target int) (direction motor.Direction, done bool) {
+func (a Actor) offset() int {
 	t := time.Now().Sub(a.t0).Seconds()
+	return int(a.amplitude * simplexnoise.Noise1(t))
+}
+
+func (a Actor) Act(pos, target int) (direction motor.Direction, done bool) {
+	return a.seeker.Act(pos, target+a.offset())
+}
 
-	offset := int(10 * simplexnoise.Noise1(t))
-	return a.seeker.Act(pos, target+offset)
+func (a Actor) Goal(pos, target int) int {
+	return target + a.offset()
 }
 
 func (a Actor) Name() string {
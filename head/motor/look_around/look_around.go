@@ -0,0 +1,59 @@
+package look_around
+
+import (
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/seeker"
+	"github.com/pkg/errors"
+	"math/rand"
+	"time"
+)
+
+// LookAround glances at random angles around the target, pausing at each one, and now
+// and then looks back at the target itself
+type LookAround struct {
+	*seeker.Seeker
+	span         int
+	minPause     time.Duration
+	maxPause     time.Duration
+	centerChance float64
+
+	offset int
+	next   time.Time
+}
+
+func New(numSteps int, params motor.Params) (*LookAround, error) {
+	if err := params.Check("range", "min_pause", "max_pause", "center_chance"); err != nil {
+		return nil, err
+	}
+
+	l := &LookAround{
+		Seeker:       seeker.New(numSteps),
+		span:         params.Steps("range", 120, numSteps),
+		minPause:     params.Duration("min_pause", 1500*time.Millisecond),
+		maxPause:     params.Duration("max_pause", 5*time.Second),
+		centerChance: params.Float("center_chance", 0.3),
+	}
+
+	if l.minPause <= 0 || l.maxPause < l.minPause {
+		return nil, errors.New("pauses must be positive, with min_pause no more than max_pause")
+	}
+
+	return l, nil
+}
+
+func (l *LookAround) Name() string {
+	return "LookAround"
+}
+
+func (l *LookAround) Goal(pos, target int) int {
+	now := time.Now()
+	if now.After(l.next) {
+		if rand.Float64() < l.centerChance || l.span <= 0 {
+			l.offset = 0
+		} else {
+			l.offset = rand.Intn(l.span+1) - l.span/2
+		}
+		l.next = now.Add(l.minPause + time.Duration(rand.Int63n(int64(l.maxPause-l.minPause)+1)))
+	}
+	return target + l.offset
+}
@@ -16,3 +16,15 @@ type Data struct {
 	Name      string `json:"name"`
 	Extra     *Extra `json:"extra"`
 }
+
+// PositionDrift is published when a head passing zero finds the magnet somewhere other
+// than where it expected it
+type PositionDrift struct {
+	HeadName  string `json:"headName"`
+	Steps     int    `json:"steps"`
+	Corrected bool   `json:"corrected"`
+}
+
+func (*PositionDrift) Name() string {
+	return "position-drift"
+}
@@ -0,0 +1,27 @@
+package motor
+
+import (
+	"github.com/minor-industries/platform/common/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// the rate of this is the motor's duty cycle
+	cEnergizedSeconds = metrics.SimpleCounter(
+		prometheus.DefaultRegisterer,
+		"head",
+		"motor_energized_seconds",
+	)
+
+	gEnergized = metrics.SimpleGauge(
+		prometheus.DefaultRegisterer,
+		"head",
+		"motor_energized",
+	)
+
+	gPositionUncertain = metrics.SimpleGauge(
+		prometheus.DefaultRegisterer,
+		"head",
+		"position_uncertain",
+	)
+)
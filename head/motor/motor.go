@@ -1,8 +1,5 @@
 package motor
 
-// TODO:
-// - Http status page
-
 type Direction int
 
 const (
@@ -0,0 +1,57 @@
+package motor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Params tune an actor, e.g. {"amplitude": 5, "period": 4}. Angles are in degrees and
+// times in seconds.
+type Params map[string]float64
+
+func (p Params) Float(name string, def float64) float64 {
+	if v, ok := p[name]; ok {
+		return v
+	}
+	return def
+}
+
+func (p Params) Duration(name string, def time.Duration) time.Duration {
+	if v, ok := p[name]; ok {
+		return time.Duration(v * float64(time.Second))
+	}
+	return def
+}
+
+// Steps reads an angle as a number of steps
+func (p Params) Steps(name string, def float64, numSteps int) int {
+	return int(math.Round(p.Float(name, def) / 360 * float64(numSteps)))
+}
+
+// Check rejects params the actor doesn't know about
+func (p Params) Check(known ...string) error {
+	var unknown []string
+	for name := range p {
+		found := false
+		for _, k := range known {
+			if name == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown params %v, expected some of %v", unknown, known)
+	}
+	return nil
+}
+
+// ActorFactory makes a fresh actor for each SetActor call
+type ActorFactory func(params Params) (Actor, error)
@@ -0,0 +1,104 @@
+package motor
+
+import (
+	"math"
+	"time"
+)
+
+// planner moves toward a goal on a trapezoidal velocity profile, one step at a time.
+// It starts from the current velocity on every step, so the goal can change mid-move
+// without a jolt: the head slows down, overshoots if it has to, and comes back.
+type planner struct {
+	maxVelocity  float64 // steps per second
+	acceleration float64 // steps per second squared
+	idle         time.Duration
+
+	velocity float64 // signed, steps per second
+}
+
+// startVelocity is the speed after one step from rest, and slow enough to stop from
+func (p *planner) startVelocity() float64 {
+	return math.Min(math.Sqrt(2*p.acceleration), p.maxVelocity)
+}
+
+// next returns the step to take toward a goal dist steps away, and how long to wait
+// before the following one
+func (p *planner) next(dist int) (Direction, time.Duration) {
+	v := math.Abs(p.velocity)
+	vStart := p.startVelocity()
+
+	dir := Forward
+	switch {
+	case p.velocity < 0:
+		dir = Backward
+	case p.velocity == 0 && dist < 0:
+		dir = Backward
+	case p.velocity == 0 && dist == 0:
+		return NoStep, p.idle
+	}
+
+	remaining := float64(dist) * float64(dir) // negative once the goal is behind us
+	stopping := v * v / (2 * p.acceleration)
+
+	var v2 float64
+	switch {
+	case remaining <= 0 && v <= vStart:
+		// slow enough to stop here, turning around on the next call if need be
+		p.velocity = 0
+		if remaining == 0 {
+			return NoStep, p.idle
+		}
+		return NoStep, time.Duration(float64(time.Second) / vStart)
+	case remaining <= 0 || stopping >= remaining:
+		v2 = math.Max(math.Sqrt(math.Max(v*v-2*p.acceleration, 0)), vStart)
+	case v > p.maxVelocity:
+		// the limit came down mid-move
+		v2 = math.Max(math.Sqrt(v*v-2*p.acceleration), p.maxVelocity)
+	default:
+		v2 = math.Min(math.Sqrt(v*v+2*p.acceleration), p.maxVelocity)
+	}
+
+	p.velocity = v2 * float64(dir)
+	dt := 2 / (v + v2) // at constant acceleration over the step
+	return dir, time.Duration(dt * float64(time.Second))
+}
+
+func (p *planner) stop() {
+	p.velocity = 0
+}
+
+// profileTime is how long a trapezoidal move takes to come to rest dist steps away,
+// starting at velocity v0
+func profileTime(v0 float64, dist float64, vmax float64, a float64) time.Duration {
+	if dist < 0 {
+		dist, v0 = -dist, -v0
+	}
+
+	var t float64
+	if v0 < 0 {
+		// heading away, so stop first
+		t += -v0 / a
+		dist += v0 * v0 / (2 * a)
+		v0 = 0
+	}
+
+	if stopping := v0 * v0 / (2 * a); stopping > dist {
+		// too fast to stop in time; overshoot and come back
+		t += v0 / a
+		return time.Duration(t*float64(time.Second)) + profileTime(0, stopping-dist, vmax, a)
+	}
+
+	vp := math.Min(vmax, math.Sqrt(a*dist+v0*v0/2))
+	if vp <= 0 {
+		return time.Duration(t * float64(time.Second))
+	}
+
+	tAccel := math.Abs(vp-v0) / a
+	sAccel := math.Abs(vp*vp-v0*v0) / (2 * a)
+	tDecel := vp / a
+	sDecel := vp * vp / (2 * a)
+	tCruise := math.Max(dist-sAccel-sDecel, 0) / vp
+
+	t += tAccel + tCruise + tDecel
+	return time.Duration(t * float64(time.Second))
+}
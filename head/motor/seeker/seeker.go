@@ -35,4 +35,8 @@ func (s *Seeker) Act(pos, target int) (motor.Direction, bool) {
 	return motor.NoStep, false
 }
 
+func (s *Seeker) Goal(pos, target int) int {
+	return target
+}
+
 func (s *Seeker) Finish(controller *motor.Controller) {}
@@ -0,0 +1,63 @@
+package sim
+
+import (
+	"github.com/minor-industries/theheads/head/sensor/magnetometer"
+	"math"
+	"math/rand"
+)
+
+// HallSensor is active while the magnet is over it
+type HallSensor struct {
+	head *Head
+}
+
+func (h *Head) HallSensor() *HallSensor {
+	return &HallSensor{head: h}
+}
+
+func (s *HallSensor) Read() (bool, error) {
+	s.head.lock.Lock()
+	defer s.head.lock.Unlock()
+
+	return math.Abs(float64(s.head.fromMagnet())) <= s.head.cfg.MagnetWidth, nil
+}
+
+// Magnetometer sees the field of the magnet as it goes past: Bz peaks right over the
+// magnet while By swings from one side to the other, plus some noise
+type Magnetometer struct {
+	head     *Head
+	hardware bool
+}
+
+// Magnetometer says it has hardware if enabled, so the head uses it to find zero rather
+// than the hall sensor
+func (h *Head) Magnetometer(enabled bool) *Magnetometer {
+	return &Magnetometer{head: h, hardware: enabled}
+}
+
+func (m *Magnetometer) HasHardware() bool {
+	return m.hardware
+}
+
+func (m *Magnetometer) Read() (*magnetometer.Reading, error) {
+	m.head.lock.Lock()
+	x := float64(m.head.fromMagnet()) / (2 * m.head.cfg.MagnetWidth)
+	m.head.lock.Unlock()
+
+	noise := func() float64 {
+		return rand.NormFloat64() * 0.005
+	}
+
+	falloff := math.Exp(-x * x)
+	bx := 0.1 + noise()
+	by := 0.8*x*falloff + noise()
+	bz := 0.05 + 1.5*falloff + noise()
+
+	return &magnetometer.Reading{
+		Bx:          bx,
+		By:          by,
+		Bz:          bz,
+		B:           math.Sqrt(bx*bx + by*by + bz*bz),
+		Temperature: 68,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package sim
+
+import (
+	"github.com/minor-industries/theheads/head/motor"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type Cfg struct {
+	// a step faster than the motor can follow is lost: past MaxVelocity, when speeding
+	// up harder than MaxAcceleration, or when turning around above PullIn
+	PullIn          float64 `envconfig:"default=60"` // steps per second the motor can start at
+	MaxVelocity     float64 `envconfig:"default=150"`
+	MaxAcceleration float64 `envconfig:"default=600"`
+
+	MagnetWidth float64 `envconfig:"default=4"` // steps either side the sensors pick the magnet up
+}
+
+// Head simulates a head's motor and the magnet passing its sensors, so everything above the
+// motor can be run without hardware. The head starts at a random angle with the magnet
+// somewhere random, as it would after being moved around while off.
+type Head struct {
+	lock sync.Mutex
+	cfg  Cfg
+	now  func() time.Time
+
+	numSteps int
+	angle    int // true position in steps, which the controller doesn't know
+	magnetAt int
+
+	energized bool
+	velocity  float64 // steps per second, negative backwards
+	lastStep  time.Time
+	lost      int
+}
+
+func New(cfg Cfg, numSteps int) *Head {
+	return &Head{
+		cfg:      cfg,
+		now:      time.Now,
+		numSteps: numSteps,
+		angle:    rand.Intn(numSteps),
+		magnetAt: rand.Intn(numSteps),
+	}
+}
+
+func (h *Head) Start() error {
+	return nil
+}
+
+func (h *Head) Off() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.energized = false
+	h.velocity = 0
+	return nil
+}
+
+func (h *Head) Step(direction motor.Direction) error {
+	if direction == motor.NoStep {
+		return nil
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	now := h.now()
+	rate := math.Inf(1)
+	if dt := now.Sub(h.lastStep).Seconds(); dt > 0 {
+		rate = 1 / dt
+	}
+	h.lastStep = now
+
+	v := float64(direction) * rate
+	if !h.energized || rate <= h.cfg.PullIn {
+		h.energized = true
+		h.velocity = v
+		h.angle += int(direction)
+		return nil
+	}
+
+	// speeding up over one step from the current velocity
+	reachable := math.Sqrt(h.velocity*h.velocity + 2*h.cfg.MaxAcceleration)
+	if rate > h.cfg.MaxVelocity || rate > reachable || h.velocity*v < 0 {
+		// the rotor can't keep up, and stalls
+		h.lost++
+		h.velocity = 0
+		return nil
+	}
+
+	h.velocity = v
+	h.angle += int(direction)
+	return nil
+}
+
+// Nudge turns the head by hand, e.g. to see a released head's position go wrong
+func (h *Head) Nudge(steps int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.angle += steps
+}
+
+// Lost counts the steps the motor missed
+func (h *Head) Lost() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.lost
+}
+
+// FromMagnet is where the head really is, in steps from the magnet
+func (h *Head) FromMagnet() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.fromMagnet()
+}
+
+func (h *Head) fromMagnet() int {
+	d := motor.Mod(h.angle-h.magnetAt, h.numSteps)
+	if d > h.numSteps/2 {
+		d -= h.numSteps
+	}
+	return d
+}
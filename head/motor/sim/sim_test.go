@@ -0,0 +1,116 @@
+package sim
+
+import (
+	"github.com/minor-industries/platform/common/broker"
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/idle"
+	"github.com/minor-industries/theheads/head/motor/seeker"
+	"github.com/minor-industries/theheads/head/motor/zero_detector"
+	magnetZeroDetector "github.com/minor-industries/theheads/head/sensor/magnetometer/zero_detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+var cfg = Cfg{
+	PullIn:          60,
+	MaxVelocity:     150,
+	MaxAcceleration: 600,
+	MagnetWidth:     4,
+}
+
+// setup runs a controller against a simulated head in simulated time
+func setup(motorCfg motor.Cfg) (*Head, *motor.Controller, func(limit time.Duration, until func() bool)) {
+	now := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+	head := New(cfg, motorCfg.NumSteps)
+	head.now = func() time.Time { return now }
+
+	b := broker.NewBroker()
+	go b.Start()
+
+	c := motor.NewController(zap.NewNop(), head, b, &motorCfg, "head-01", idle.New())
+
+	run := func(limit time.Duration, until func() bool) {
+		end := now.Add(limit)
+		for !until() {
+			if now.After(end) {
+				panic("ran out of time")
+			}
+			now = now.Add(c.Tick())
+		}
+	}
+	return head, c, run
+}
+
+var motorCfg = motor.Cfg{
+	NumSteps:              200,
+	StepSpeed:             30,
+	DirectionChangePauses: 10,
+	MaxVelocity:           45,
+	Acceleration:          90,
+	VelocityLimit:         400,
+	AccelerationLimit:     4000,
+}
+
+func searching(c *motor.Controller) func() bool {
+	return func() bool {
+		return c.GetState().ZeroStatus != motor.ZeroSearching
+	}
+}
+
+func TestFindZero(t *testing.T) {
+	t.Run("hall sensor", func(t *testing.T) {
+		head, c, run := setup(motorCfg)
+		c.FindZero(zero_detector.NewDetector(zap.NewNop(), head.HallSensor(), 200, 10))
+		run(time.Minute, searching(c))
+
+		require.Equal(t, motor.ZeroFound, c.GetState().ZeroStatus)
+		assert.InDelta(t, 0, head.FromMagnet(), 1)
+		assert.Zero(t, head.Lost())
+	})
+
+	t.Run("magnetometer", func(t *testing.T) {
+		head, c, run := setup(motorCfg)
+		c.FindZero(magnetZeroDetector.NewZeroDetector(
+			zap.NewNop(),
+			head.Magnetometer(true),
+			200,
+			10,
+			func() int { return c.GetState().Pos },
+			func(string, []byte) {},
+		))
+		run(2*time.Minute, searching(c))
+
+		require.Equal(t, motor.ZeroFound, c.GetState().ZeroStatus)
+		assert.InDelta(t, 0, head.FromMagnet(), 1)
+	})
+}
+
+func TestLostSteps(t *testing.T) {
+	head, c, run := setup(motorCfg)
+	c.SetActor(seeker.New(200))
+	start := head.FromMagnet()
+	moved := func() int {
+		return motor.Mod(head.FromMagnet()-start, 200)
+	}
+
+	settled := func() bool {
+		state := c.GetState()
+		return state.Pos == state.Target && state.Velocity == 0
+	}
+
+	// within what the motor can do, the head ends up where the controller thinks it is
+	c.SetTargetRotation(90)
+	run(time.Minute, settled)
+	assert.Zero(t, head.Lost())
+	assert.Equal(t, 50, moved())
+
+	// too fast, and it doesn't
+	c.SetActorWithMotion(seeker.New(200), motor.Motion{MaxVelocity: 300, Acceleration: 3000})
+	c.SetTargetRotation(216) // 120 steps
+	run(time.Minute, settled)
+	assert.Positive(t, head.Lost())
+	assert.Equal(t, 120-head.Lost(), moved())
+}
@@ -0,0 +1,155 @@
+package stepdir
+
+import (
+	"fmt"
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/pkg/errors"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/host/v3"
+	"time"
+)
+
+type Cfg struct {
+	StepPin   string `envconfig:"default=GPIO17"`
+	DirPin    string `envconfig:"default=GPIO27"`
+	EnablePin string `envconfig:"optional"` // active low; without it the motor can't be released
+
+	// as set on the driver's MS pins, or over UART. Each step of the controller is still a
+	// full step, made of this many pulses.
+	Microsteps int           `envconfig:"default=16"`
+	PulseWidth time.Duration `envconfig:"default=5us"`
+	InvertDir  bool          `envconfig:"optional"`
+}
+
+// Pin is the part of a GPIO pin the driver needs
+type Pin interface {
+	Out(l gpio.Level) error
+}
+
+// Motor drives a step/dir driver such as an A4988 or TMC2209
+type Motor struct {
+	step   Pin
+	dir    Pin
+	enable Pin // may be nil
+
+	microsteps int
+	pulse      time.Duration
+	invertDir  bool
+	sleep      func(time.Duration)
+
+	direction motor.Direction
+	enabled   bool
+}
+
+func New(step, dir, enable Pin, microsteps int, pulse time.Duration, invertDir bool) *Motor {
+	if err := CheckMicrosteps(microsteps); err != nil {
+		panic(err)
+	}
+
+	return &Motor{
+		step:       step,
+		dir:        dir,
+		enable:     enable,
+		microsteps: microsteps,
+		pulse:      pulse,
+		invertDir:  invertDir,
+		sleep:      time.Sleep,
+	}
+}
+
+func CheckMicrosteps(microsteps int) error {
+	if microsteps < 1 || microsteps > 256 || microsteps&(microsteps-1) != 0 {
+		return fmt.Errorf("microsteps must be a power of two up to 256, not %d", microsteps)
+	}
+	return nil
+}
+
+// Open sets up the motor on the Pi's GPIO pins
+func Open(cfg *Cfg) (*Motor, error) {
+	if _, err := host.Init(); err != nil {
+		return nil, errors.Wrap(err, "init host")
+	}
+
+	byName := func(name string) (Pin, error) {
+		pin := gpioreg.ByName(name)
+		if pin == nil {
+			return nil, fmt.Errorf("no such pin %q", name)
+		}
+		return pin, nil
+	}
+
+	step, err := byName(cfg.StepPin)
+	if err != nil {
+		return nil, errors.Wrap(err, "step pin")
+	}
+	dir, err := byName(cfg.DirPin)
+	if err != nil {
+		return nil, errors.Wrap(err, "dir pin")
+	}
+
+	var enable Pin
+	if cfg.EnablePin != "" {
+		enable, err = byName(cfg.EnablePin)
+		if err != nil {
+			return nil, errors.Wrap(err, "enable pin")
+		}
+	}
+
+	return New(step, dir, enable, cfg.Microsteps, cfg.PulseWidth, cfg.InvertDir), nil
+}
+
+func (m *Motor) Start() error {
+	if err := m.step.Out(gpio.Low); err != nil {
+		return errors.Wrap(err, "step")
+	}
+	// energized on the first step, the same as the hat
+	return m.Off()
+}
+
+func (m *Motor) Step(direction motor.Direction) error {
+	if direction == motor.NoStep {
+		return nil
+	}
+
+	if !m.enabled && m.enable != nil {
+		if err := m.enable.Out(gpio.Low); err != nil {
+			return errors.Wrap(err, "enable")
+		}
+	}
+	m.enabled = true
+
+	if direction != m.direction {
+		forward := direction == motor.Forward
+		if m.invertDir {
+			forward = !forward
+		}
+		if err := m.dir.Out(gpio.Level(forward)); err != nil {
+			return errors.Wrap(err, "dir")
+		}
+		m.direction = direction
+		m.sleep(m.pulse) // setup time before the next pulse
+	}
+
+	for i := 0; i < m.microsteps; i++ {
+		if err := m.step.Out(gpio.High); err != nil {
+			return errors.Wrap(err, "step")
+		}
+		m.sleep(m.pulse)
+		if err := m.step.Out(gpio.Low); err != nil {
+			return errors.Wrap(err, "step")
+		}
+		m.sleep(m.pulse)
+	}
+
+	return nil
+}
+
+// Off releases the coils, if there's an enable pin to do it with
+func (m *Motor) Off() error {
+	m.enabled = false
+	if m.enable == nil {
+		return nil
+	}
+	return errors.Wrap(m.enable.Out(gpio.High), "disable")
+}
@@ -0,0 +1,85 @@
+package stepdir
+
+import (
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"periph.io/x/conn/v3/gpio"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pins records every change on the fake pins, e.g. "step=1"
+type pins struct {
+	events []string
+}
+
+type pin struct {
+	name string
+	pins *pins
+}
+
+func (p *pin) Out(l gpio.Level) error {
+	v := "0"
+	if l {
+		v = "1"
+	}
+	p.pins.events = append(p.pins.events, p.name+"="+v)
+	return nil
+}
+
+func (p *pins) take() string {
+	result := strings.Join(p.events, " ")
+	p.events = nil
+	return result
+}
+
+func TestMotor(t *testing.T) {
+	p := &pins{}
+	m := New(&pin{"step", p}, &pin{"dir", p}, &pin{"en", p}, 2, time.Microsecond, false)
+	var slept time.Duration
+	m.sleep = func(d time.Duration) { slept += d }
+
+	require.NoError(t, m.Start())
+	assert.Equal(t, "step=0 en=1", p.take())
+
+	// enabled on the first step, with the direction set before the pulses
+	require.NoError(t, m.Step(motor.Forward))
+	assert.Equal(t, "en=0 dir=1 step=1 step=0 step=1 step=0", p.take())
+	assert.Equal(t, 5*time.Microsecond, slept)
+
+	require.NoError(t, m.Step(motor.Forward))
+	assert.Equal(t, "step=1 step=0 step=1 step=0", p.take())
+
+	require.NoError(t, m.Step(motor.NoStep))
+	assert.Equal(t, "", p.take())
+
+	require.NoError(t, m.Step(motor.Backward))
+	assert.Equal(t, "dir=0 step=1 step=0 step=1 step=0", p.take())
+
+	require.NoError(t, m.Off())
+	assert.Equal(t, "en=1", p.take())
+	require.NoError(t, m.Step(motor.Backward))
+	assert.Equal(t, "en=0 step=1 step=0 step=1 step=0", p.take())
+}
+
+func TestMotor_NoEnablePin(t *testing.T) {
+	p := &pins{}
+	m := New(&pin{"step", p}, &pin{"dir", p}, nil, 1, 0, true)
+	m.sleep = func(time.Duration) {}
+
+	require.NoError(t, m.Start())
+	require.NoError(t, m.Step(motor.Forward))
+	require.NoError(t, m.Off())
+	assert.Equal(t, "step=0 dir=0 step=1 step=0", p.take())
+}
+
+func TestCheckMicrosteps(t *testing.T) {
+	for _, ok := range []int{1, 2, 16, 256} {
+		assert.NoError(t, CheckMicrosteps(ok))
+	}
+	for _, bad := range []int{0, 3, 12, 512} {
+		assert.Error(t, CheckMicrosteps(bad))
+	}
+}
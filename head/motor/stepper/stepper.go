@@ -1,6 +1,7 @@
 package stepper
 
 import (
+	"fmt"
 	"github.com/minor-industries/theheads/head/motor"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -13,18 +14,47 @@ var stepMap = map[motor.Direction]i2c.AdafruitDirection{
 	motor.Backward: i2c.AdafruitBackward,
 }
 
+// styles are how the hat makes a full step: single drives one coil at a time, double
+// both for more torque, and interleave and microstep go through the positions in between
+// for a smoother, quieter turn. The steps are how many of the hat's steps make a full one.
+var styles = map[string]struct {
+	style i2c.AdafruitStepStyle
+	steps int
+}{
+	"single":     {i2c.AdafruitSingle, 1},
+	"double":     {i2c.AdafruitDouble, 1},
+	"interleave": {i2c.AdafruitInterleave, 2},
+	"microstep":  {i2c.AdafruitMicrostep, 1}, // the driver makes the microsteps itself
+}
+
+func CheckStyle(style string) error {
+	if _, ok := styles[style]; !ok {
+		return fmt.Errorf("unknown step style %q", style)
+	}
+	return nil
+}
+
+// hat is the part of the gobot driver that's used, so it can be faked
+type hat interface {
+	Start() error
+	Step(motor, steps int, dir i2c.AdafruitDirection, style i2c.AdafruitStepStyle) error
+	RunDCMotor(motor int, dir i2c.AdafruitDirection) error
+}
+
 type Motor struct {
 	logger *zap.Logger
-	driver *i2c.AdafruitMotorHatDriver
+	driver hat
 
 	motorID int
+	style   i2c.AdafruitStepStyle
+	steps   int
 }
 
 func (s *Motor) Start() error {
 	return s.driver.Start()
 }
 
-func New(logger *zap.Logger, motorID int) (*Motor, error) {
+func New(logger *zap.Logger, motorID int, style string) (*Motor, error) {
 	r := raspi.NewAdaptor()
 	driver := i2c.NewAdafruitMotorHatDriver(r)
 
@@ -35,10 +65,21 @@ func New(logger *zap.Logger, motorID int) (*Motor, error) {
 		return nil, errors.Wrap(err, "set motor speed")
 	}
 
+	return newMotor(logger, driver, motorID, style)
+}
+
+func newMotor(logger *zap.Logger, driver hat, motorID int, style string) (*Motor, error) {
+	s, ok := styles[style]
+	if !ok {
+		return nil, fmt.Errorf("unknown step style %q", style)
+	}
+
 	return &Motor{
 		logger:  logger,
 		driver:  driver,
 		motorID: motorID,
+		style:   s.style,
+		steps:   s.steps,
 	}, nil
 }
 
@@ -47,7 +88,7 @@ func (s *Motor) Step(direction motor.Direction) error {
 		return nil
 	}
 	dir := stepMap[direction]
-	err := s.driver.Step(s.motorID, 1, dir, i2c.AdafruitSingle)
+	err := s.driver.Step(s.motorID, s.steps, dir, s.style)
 	return errors.Wrap(err, "step")
 }
 
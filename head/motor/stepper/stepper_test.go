@@ -0,0 +1,66 @@
+package stepper
+
+import (
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gobot.io/x/gobot/drivers/i2c"
+	"testing"
+)
+
+type step struct {
+	motor int
+	steps int
+	dir   i2c.AdafruitDirection
+	style i2c.AdafruitStepStyle
+}
+
+type fakeHat struct {
+	steps    []step
+	released bool
+}
+
+func (f *fakeHat) Start() error { return nil }
+
+func (f *fakeHat) Step(motor, steps int, dir i2c.AdafruitDirection, style i2c.AdafruitStepStyle) error {
+	f.steps = append(f.steps, step{motor, steps, dir, style})
+	return nil
+}
+
+func (f *fakeHat) RunDCMotor(motor int, dir i2c.AdafruitDirection) error {
+	f.released = dir == i2c.AdafruitRelease
+	return nil
+}
+
+func TestMotor(t *testing.T) {
+	hat := &fakeHat{}
+	m, err := newMotor(zap.NewNop(), hat, 1, "interleave")
+	require.NoError(t, err)
+
+	require.NoError(t, m.Step(motor.Forward))
+	require.NoError(t, m.Step(motor.NoStep))
+	require.NoError(t, m.Step(motor.Backward))
+	assert.Equal(t, []step{
+		{1, 2, i2c.AdafruitForward, i2c.AdafruitInterleave}, // two half steps make a full one
+		{1, 2, i2c.AdafruitBackward, i2c.AdafruitInterleave},
+	}, hat.steps)
+
+	require.NoError(t, m.Off())
+	assert.True(t, hat.released)
+
+	for style, expected := range map[string]step{
+		"single":    {0, 1, i2c.AdafruitForward, i2c.AdafruitSingle},
+		"double":    {0, 1, i2c.AdafruitForward, i2c.AdafruitDouble},
+		"microstep": {0, 1, i2c.AdafruitForward, i2c.AdafruitMicrostep},
+	} {
+		hat := &fakeHat{}
+		m, err := newMotor(zap.NewNop(), hat, 0, style)
+		require.NoError(t, err)
+		require.NoError(t, m.Step(motor.Forward))
+		assert.Equal(t, []step{expected}, hat.steps, style)
+	}
+
+	_, err = newMotor(zap.NewNop(), hat, 0, "wave")
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package sweep
+
+import (
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/seeker"
+	"github.com/pkg/errors"
+	"math"
+	"time"
+)
+
+// Sweep scans back and forth, easing in and out at each end. By default it scans width
+// degrees centered on the target; from and to give fixed angles instead, sweeping
+// forwards from one to the other.
+type Sweep struct {
+	*seeker.Seeker
+	numSteps int
+	t0       time.Time
+	period   time.Duration
+
+	fixed bool
+	from  int
+	width int
+}
+
+func New(numSteps int, params motor.Params) (*Sweep, error) {
+	if err := params.Check("width", "from", "to", "period"); err != nil {
+		return nil, err
+	}
+
+	_, hasFrom := params["from"]
+	_, hasTo := params["to"]
+	if hasFrom != hasTo {
+		return nil, errors.New("from and to go together")
+	}
+
+	s := &Sweep{
+		Seeker:   seeker.New(numSteps),
+		numSteps: numSteps,
+		t0:       time.Now(),
+		period:   params.Duration("period", 10*time.Second),
+		fixed:    hasFrom,
+		width:    params.Steps("width", 90, numSteps),
+	}
+
+	if s.period <= 0 {
+		return nil, errors.New("period must be positive")
+	}
+
+	if s.fixed {
+		s.from = params.Steps("from", 0, numSteps)
+		s.width = motor.Mod(params.Steps("to", 0, numSteps)-s.from, numSteps)
+	}
+
+	return s, nil
+}
+
+func (s *Sweep) Name() string {
+	return "Sweep"
+}
+
+func (s *Sweep) Goal(pos, target int) int {
+	from := s.from
+	if !s.fixed {
+		from = target - s.width/2
+	}
+
+	t := time.Since(s.t0).Seconds() / s.period.Seconds()
+	phase := (1 - math.Cos(2*math.Pi*t)) / 2
+	return from + int(math.Round(phase*float64(s.width)))
+}
@@ -0,0 +1,134 @@
+package timeline
+
+import (
+	"fmt"
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/seeker"
+	"github.com/pkg/errors"
+	"math"
+	"time"
+)
+
+var easings = map[string]func(x float64) float64{
+	"":       linear,
+	"linear": linear,
+	"ease-in": func(x float64) float64 {
+		return x * x * x
+	},
+	"ease-out": func(x float64) float64 {
+		return 1 - math.Pow(1-x, 3)
+	},
+	"ease-in-out": func(x float64) float64 {
+		return x * x * (3 - 2*x)
+	},
+	"step": func(x float64) float64 {
+		return 0 // jumps once the keyframe is reached
+	},
+}
+
+func linear(x float64) float64 {
+	return x
+}
+
+type Keyframe struct {
+	At     time.Duration
+	Theta  float64 // degrees
+	Easing string
+	Actor  motor.Planned // may be nil
+}
+
+// Timeline moves the head through keyframes against its own clock, so heads given the same
+// start time move together however late the commands reached them
+type Timeline struct {
+	*seeker.Seeker
+	name      string
+	numSteps  int
+	start     time.Time
+	keyframes []Keyframe
+
+	now func() time.Time
+}
+
+func New(name string, numSteps int, start time.Time, keyframes []Keyframe) (*Timeline, error) {
+	if len(keyframes) == 0 {
+		return nil, errors.New("no keyframes")
+	}
+
+	for i, kf := range keyframes {
+		if i > 0 && kf.At <= keyframes[i-1].At {
+			return nil, fmt.Errorf("keyframe %d isn't after the one before", i)
+		}
+		if _, ok := easings[kf.Easing]; !ok {
+			return nil, fmt.Errorf("keyframe %d: unknown easing %q", i, kf.Easing)
+		}
+	}
+
+	return &Timeline{
+		Seeker:    seeker.New(numSteps),
+		name:      name,
+		numSteps:  numSteps,
+		start:     start,
+		keyframes: keyframes,
+		now:       time.Now,
+	}, nil
+}
+
+func (tl *Timeline) Name() string {
+	return "Timeline"
+}
+
+// at returns the timeline's angle and the actor in effect t into it
+func (tl *Timeline) at(t time.Duration) (float64, motor.Planned) {
+	kfs := tl.keyframes
+	if t < kfs[0].At {
+		return kfs[0].Theta, nil
+	}
+
+	i := len(kfs) - 1
+	for j := range kfs {
+		if kfs[j].At > t {
+			i = j - 1
+			break
+		}
+	}
+
+	if i == len(kfs)-1 {
+		return kfs[i].Theta, kfs[i].Actor
+	}
+
+	from, to := kfs[i], kfs[i+1]
+	x := float64(t-from.At) / float64(to.At-from.At)
+	return from.Theta + (to.Theta-from.Theta)*easings[to.Easing](x), from.Actor
+}
+
+func (tl *Timeline) Goal(pos, target int) int {
+	theta, actor := tl.at(tl.now().Sub(tl.start))
+	goal := int(math.Round(theta / 360 * float64(tl.numSteps)))
+	if actor != nil {
+		return actor.Goal(pos, goal)
+	}
+	return goal
+}
+
+// Motion asks for the head's limits, so it keeps up with the timeline rather than lagging
+// behind on its default speed
+func (tl *Timeline) Motion() motor.Motion {
+	return motor.Motion{
+		MaxVelocity:  math.Inf(1),
+		Acceleration: math.Inf(1),
+	}
+}
+
+func (tl *Timeline) Sequence() (string, float64) {
+	t := tl.now().Sub(tl.start)
+	end := tl.keyframes[len(tl.keyframes)-1].At
+
+	switch {
+	case t < 0:
+		return tl.name, 0
+	case t >= end:
+		return tl.name, 1
+	default:
+		return tl.name, float64(t) / float64(end)
+	}
+}
@@ -0,0 +1,67 @@
+package timeline
+
+import (
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type offset struct {
+	by int
+}
+
+func (o *offset) Name() string                                { return "offset" }
+func (o *offset) Act(pos, target int) (motor.Direction, bool) { return motor.NoStep, false }
+func (o *offset) Finish(controller *motor.Controller)         {}
+func (o *offset) Goal(pos, target int) int                    { return target + o.by }
+
+func TestTimeline(t *testing.T) {
+	start := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+
+	tl, err := New("dance", 200, start, []Keyframe{
+		{At: 0, Theta: 90},
+		{At: 2 * time.Second, Theta: 270},
+		{At: 4 * time.Second, Theta: 450, Easing: "ease-in-out", Actor: &offset{by: 3}},
+		{At: 6 * time.Second, Theta: 0, Easing: "step"},
+	})
+	require.NoError(t, err)
+
+	at := func(d time.Duration) int {
+		tl.now = func() time.Time { return start.Add(d) }
+		return tl.Goal(0, 0)
+	}
+
+	assert.Equal(t, 50, at(-time.Second))   // heading for the first keyframe
+	assert.Equal(t, 100, at(time.Second))   // linear, halfway to 270
+	assert.Equal(t, 200, at(3*time.Second)) // eases through the middle
+	assert.Less(t, at(2500*time.Millisecond), 175)
+	assert.Equal(t, 253, at(5*time.Second)) // holds 450 with the actor on top
+	assert.Equal(t, 0, at(7*time.Second))   // stepped, and holds the last keyframe
+
+	for _, tc := range []struct {
+		at       time.Duration
+		progress float64
+	}{
+		{-time.Second, 0},
+		{3 * time.Second, 0.5},
+		{time.Minute, 1},
+	} {
+		tl.now = func() time.Time { return start.Add(tc.at) }
+		name, progress := tl.Sequence()
+		assert.Equal(t, "dance", name)
+		assert.InDelta(t, tc.progress, progress, 1e-9)
+	}
+}
+
+func TestTimeline_Invalid(t *testing.T) {
+	for name, kfs := range map[string][]Keyframe{
+		"empty":        nil,
+		"out of order": {{At: time.Second}, {At: time.Second}},
+		"easing":       {{At: 0}, {At: time.Second, Easing: "bounce"}},
+	} {
+		_, err := New("bad", 200, time.Now(), kfs)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,149 @@
+package tmc2209
+
+import (
+	"fmt"
+	"github.com/goburrow/serial"
+	"github.com/pkg/errors"
+	"io"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// registers, see the TMC2209 datasheet
+const (
+	regGCONF     = 0x00
+	regIholdIrun = 0x10
+	regCHOPCONF  = 0x6C
+)
+
+const (
+	gconfEnSpreadCycle   = 1 << 2
+	gconfPdnDisable      = 1 << 6 // the UART pin is for UART only
+	gconfMstepRegSelect  = 1 << 7 // microsteps from CHOPCONF rather than the MS pins
+	gconfMultistepFilter = 1 << 8
+
+	chopconfDefault = 0x10000053 // interpolating to 256 microsteps
+	iholdDelay      = 8
+)
+
+type Cfg struct {
+	Port          string  `envconfig:"optional"`    // e.g. /dev/ttyAMA0; empty to leave the driver as its pins set it
+	Address       int     `envconfig:"default=0"`   // 0 to 3, set by the MS1 and MS2 pins
+	RunCurrent    int     `envconfig:"default=600"` // mA rms
+	HoldCurrent   int     `envconfig:"default=300"`
+	StealthChop   bool    `envconfig:"default=true"` // quiet, at the cost of torque at speed
+	SenseResistor float64 `envconfig:"default=0.11"` // ohms
+}
+
+type Settings struct {
+	RunCurrent  int // mA rms
+	HoldCurrent int
+	StealthChop bool
+	Microsteps  int
+}
+
+// Driver configures a TMC2209 over its single wire UART. It only writes: reading back
+// needs the echo of each write to be filtered out, and the head doesn't need to.
+type Driver struct {
+	port  io.Writer
+	addr  byte
+	sense float64
+}
+
+func New(port io.Writer, addr int, senseResistor float64) *Driver {
+	if addr < 0 || addr > 3 {
+		panic("tmc2209 address must be from 0 to 3")
+	}
+	return &Driver{port: port, addr: byte(addr), sense: senseResistor}
+}
+
+func Open(cfg *Cfg) (*Driver, io.Closer, error) {
+	port, err := serial.Open(&serial.Config{
+		Address:  cfg.Port,
+		BaudRate: 115200,
+		DataBits: 8,
+		StopBits: 1,
+		Parity:   "N",
+		Timeout:  time.Second,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "open serial port")
+	}
+	return New(port, cfg.Address, cfg.SenseResistor), port, nil
+}
+
+func (d *Driver) Configure(settings Settings) error {
+	gconf := uint32(gconfPdnDisable | gconfMstepRegSelect | gconfMultistepFilter)
+	if !settings.StealthChop {
+		gconf |= gconfEnSpreadCycle
+	}
+	if err := d.write(regGCONF, gconf); err != nil {
+		return errors.Wrap(err, "gconf")
+	}
+
+	mres, err := mres(settings.Microsteps)
+	if err != nil {
+		return err
+	}
+	chopconf := uint32(chopconfDefault)&^(0xf<<24) | mres<<24
+	if err := d.write(regCHOPCONF, chopconf); err != nil {
+		return errors.Wrap(err, "chopconf")
+	}
+
+	ihold := d.currentScale(settings.HoldCurrent)
+	irun := d.currentScale(settings.RunCurrent)
+	if err := d.write(regIholdIrun, ihold|irun<<8|iholdDelay<<16); err != nil {
+		return errors.Wrap(err, "ihold_irun")
+	}
+
+	return nil
+}
+
+// mres encodes microsteps as CHOPCONF does, from 0 for 256 up to 8 for full steps
+func mres(microsteps int) (uint32, error) {
+	if microsteps < 1 || microsteps > 256 || microsteps&(microsteps-1) != 0 {
+		return 0, fmt.Errorf("microsteps must be a power of two up to 256, not %d", microsteps)
+	}
+	return uint32(8 - bits.TrailingZeros(uint(microsteps))), nil
+}
+
+// currentScale turns mA rms into the driver's 0 to 31 current scale, with vsense unset
+func (d *Driver) currentScale(mA int) uint32 {
+	cs := 32*float64(mA)/1000*math.Sqrt2*(d.sense+0.02)/0.325 - 1
+	return uint32(math.Max(0, math.Min(31, math.Round(cs))))
+}
+
+func (d *Driver) write(reg byte, value uint32) error {
+	datagram := []byte{
+		0x05, // sync
+		d.addr,
+		reg | 0x80, // write
+		byte(value >> 24),
+		byte(value >> 16),
+		byte(value >> 8),
+		byte(value),
+		0,
+	}
+	datagram[7] = crc(datagram[:7])
+
+	_, err := d.port.Write(datagram)
+	return err
+}
+
+// crc is the datagram checksum from the datasheet, a CRC8 over the bits least significant
+// first
+func crc(data []byte) byte {
+	var c byte
+	for _, b := range data {
+		for i := 0; i < 8; i++ {
+			if (c>>7)^(b&1) != 0 {
+				c = c<<1 ^ 0x07
+			} else {
+				c <<= 1
+			}
+			b >>= 1
+		}
+	}
+	return c
+}
@@ -0,0 +1,65 @@
+package tmc2209
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// writes splits what was written to the fake UART into register writes
+func writes(t *testing.T, buf *bytes.Buffer, addr byte) map[byte]uint32 {
+	require.Zero(t, buf.Len()%8)
+
+	result := map[byte]uint32{}
+	for buf.Len() > 0 {
+		datagram := buf.Next(8)
+		assert.Equal(t, byte(0x05), datagram[0])
+		assert.Equal(t, addr, datagram[1])
+		assert.Equal(t, crc(datagram[:7]), datagram[7])
+		require.NotZero(t, datagram[2]&0x80, "not a write")
+		result[datagram[2]&^0x80] = binary.BigEndian.Uint32(datagram[3:7])
+	}
+	return result
+}
+
+func TestConfigure(t *testing.T) {
+	buf := &bytes.Buffer{}
+	d := New(buf, 2, 0.11)
+
+	require.NoError(t, d.Configure(Settings{
+		RunCurrent:  800,
+		HoldCurrent: 400,
+		StealthChop: true,
+		Microsteps:  16,
+	}))
+
+	regs := writes(t, buf, 2)
+	assert.Equal(t, uint32(0x1c0), regs[regGCONF])
+	assert.Equal(t, uint32(0x14000053), regs[regCHOPCONF])
+
+	// 800mA is 13 on the current scale, 400mA is 6
+	assert.Equal(t, uint32(8<<16|13<<8|6), regs[regIholdIrun])
+
+	require.NoError(t, d.Configure(Settings{RunCurrent: 5000, Microsteps: 1}))
+	regs = writes(t, buf, 2)
+	assert.Equal(t, uint32(0x1c4), regs[regGCONF]) // spreadCycle
+	assert.Equal(t, uint32(0x18000053), regs[regCHOPCONF])
+	assert.Equal(t, uint32(8<<16|31<<8), regs[regIholdIrun])
+
+	assert.Error(t, d.Configure(Settings{Microsteps: 3}))
+}
+
+func TestCRC(t *testing.T) {
+	// every bit counts
+	datagram := []byte{0x05, 0x00, 0x80, 0x00, 0x00, 0x01, 0xc0}
+	c := crc(datagram)
+	for i := range datagram {
+		for bit := 0; bit < 8; bit++ {
+			datagram[i] ^= 1 << bit
+			assert.NotEqual(t, c, crc(datagram))
+			datagram[i] ^= 1 << bit
+		}
+	}
+}
@@ -3,6 +3,7 @@ package zero_detector
 import (
 	"github.com/minor-industries/theheads/head/motor"
 	"github.com/minor-industries/theheads/head/sensor"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -19,6 +20,7 @@ type Detector struct {
 
 	ch     chan result
 	logger *zap.Logger
+	err    error // set before ch is closed
 }
 
 func (d *Detector) Name() string {
@@ -79,7 +81,8 @@ func (d *Detector) stepUntil(
 		value, err := d.sensor.Read()
 		if err != nil {
 			d.logger.Error("error reading sensor", zap.Error(err))
-			return true // TODO: make this an actual error
+			d.err = errors.Wrap(err, "read sensor")
+			return true
 		}
 
 		if value == targetValue {
@@ -150,6 +153,8 @@ func (d *Detector) controller() {
 		d.logger.Info("steps to zero", zap.Int("steps", steps))
 	} else {
 		d.logger.Error("sensor was not active during scan")
+		d.err = errors.New("sensor was not active during scan")
+		return
 	}
 
 	// Step backward if the path is shorter
@@ -175,5 +180,9 @@ func (d *Detector) controller() {
 }
 
 func (d *Detector) Finish(controller *motor.Controller) {
+	if d.err != nil {
+		controller.ZeroFailed(d.err.Error())
+		return
+	}
 	controller.SetCurrentPositionAsZero()
 }
@@ -0,0 +1,173 @@
+package magnetometer
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Sample is the field averaged over a full turn of the head, at one temperature. The
+// magnet averages out to the same thing every turn, so what changes between samples is
+// the sensor's own drift.
+type Sample struct {
+	At          time.Time
+	Temperature float64 // Fahrenheit
+	Bx, By, Bz  float64
+}
+
+// samples closer together than this replace each other
+const sameTemperature = 2.0
+
+// Calibration compensates readings for the sensor drifting with temperature: each axis
+// is assumed to drift linearly, and readings are brought back to what they would have
+// been at the reference temperature
+type Calibration struct {
+	Samples []Sample
+
+	Reference                 float64 // Fahrenheit
+	OffsetX, OffsetY, OffsetZ float64 // mT at the reference temperature
+	CoefX, CoefY, CoefZ       float64 // mT per degree
+
+	Map *FieldMap `json:",omitempty"` // only made when the head was zeroed
+}
+
+// Add fits the calibration again with the sample
+func (c *Calibration) Add(sample Sample) *Calibration {
+	var samples []Sample
+	for _, s := range c.Samples {
+		if math.Abs(s.Temperature-sample.Temperature) >= sameTemperature {
+			samples = append(samples, s)
+		}
+	}
+	fit := Fit(append(samples, sample))
+	fit.Map = c.Map
+	return fit
+}
+
+// Fit needs samples at two or more temperatures to find the drift; with just one it only
+// has the offsets
+func Fit(samples []Sample) *Calibration {
+	c := &Calibration{Samples: samples}
+	if len(samples) == 0 {
+		return c
+	}
+
+	for _, s := range samples {
+		c.Reference += s.Temperature / float64(len(samples))
+	}
+
+	axis := func(get func(s Sample) float64) (offset, coef float64) {
+		var num, den float64
+		for _, s := range samples {
+			offset += get(s) / float64(len(samples))
+		}
+		for _, s := range samples {
+			dt := s.Temperature - c.Reference
+			num += dt * (get(s) - offset)
+			den += dt * dt
+		}
+		if den > 0 {
+			coef = num / den
+		}
+		return offset, coef
+	}
+
+	c.OffsetX, c.CoefX = axis(func(s Sample) float64 { return s.Bx })
+	c.OffsetY, c.CoefY = axis(func(s Sample) float64 { return s.By })
+	c.OffsetZ, c.CoefZ = axis(func(s Sample) float64 { return s.Bz })
+	return c
+}
+
+func (c *Calibration) Apply(read *Reading) *Reading {
+	dt := read.Temperature - c.Reference
+	bx := read.Bx - c.CoefX*dt
+	by := read.By - c.CoefY*dt
+	bz := read.Bz - c.CoefZ*dt
+
+	return &Reading{
+		Bx:          bx,
+		By:          by,
+		Bz:          bz,
+		B:           math.Sqrt(bx*bx + by*by + bz*bz),
+		Temperature: read.Temperature,
+	}
+}
+
+func LoadCalibration(filename string) (*Calibration, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "read")
+	}
+
+	c := &Calibration{}
+	if err := json.Unmarshal(content, c); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	return c, nil
+}
+
+func (c *Calibration) Save(filename string) error {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return errors.Wrap(err, "mkdir")
+	}
+	return errors.Wrap(os.WriteFile(filename, content, 0o644), "write")
+}
+
+// Compensated applies the calibration to everything read from the sensor
+type Compensated struct {
+	Sensor
+
+	lock        sync.Mutex
+	calibration *Calibration
+}
+
+func NewCompensated(sensor Sensor, calibration *Calibration) *Compensated {
+	if calibration == nil {
+		calibration = &Calibration{}
+	}
+	return &Compensated{Sensor: sensor, calibration: calibration}
+}
+
+func (c *Compensated) Read() (*Reading, error) {
+	read, err := c.Sensor.Read()
+	if err != nil {
+		return nil, err
+	}
+	return c.Calibration().Apply(read), nil
+}
+
+// Estimate reads where the head is from the field map, if calibrating made one
+func (c *Compensated) Estimate() (Estimate, error) {
+	read, err := c.Read()
+	if err != nil {
+		return Estimate{}, err
+	}
+	return c.Calibration().Estimate(read)
+}
+
+// Raw reads without compensation, for calibrating
+func (c *Compensated) Raw() (*Reading, error) {
+	return c.Sensor.Read()
+}
+
+func (c *Compensated) Calibration() *Calibration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.calibration
+}
+
+func (c *Compensated) SetCalibration(calibration *Calibration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.calibration = calibration
+}
@@ -0,0 +1,59 @@
+package magnetometer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func drifting(temperature float64) Sample {
+	return Sample{
+		Temperature: temperature,
+		Bx:          1.0 + 0.02*(temperature-70),
+		By:          -0.5 - 0.01*(temperature-70),
+		Bz:          3.0,
+	}
+}
+
+func TestFit(t *testing.T) {
+	c := Fit([]Sample{drifting(60), drifting(70), drifting(80)})
+
+	assert.InDelta(t, 70.0, c.Reference, 1e-9)
+	assert.InDelta(t, 1.0, c.OffsetX, 1e-9)
+	assert.InDelta(t, 0.02, c.CoefX, 1e-9)
+	assert.InDelta(t, -0.01, c.CoefY, 1e-9)
+	assert.InDelta(t, 0.0, c.CoefZ, 1e-9)
+
+	s := drifting(90)
+	read := c.Apply(&Reading{Bx: s.Bx, By: s.By, Bz: s.Bz, Temperature: 90})
+	assert.InDelta(t, 1.0, read.Bx, 1e-9)
+	assert.InDelta(t, -0.5, read.By, 1e-9)
+	assert.InDelta(t, 3.0, read.Bz, 1e-9)
+}
+
+func TestFit_OneTemperature(t *testing.T) {
+	c := Fit([]Sample{drifting(80)})
+	assert.Equal(t, 0.0, c.CoefX)
+
+	read := c.Apply(&Reading{Bx: 1, By: 2, Bz: 3, Temperature: 60})
+	assert.Equal(t, 1.0, read.Bx)
+}
+
+func TestCalibration_Add(t *testing.T) {
+	c := Fit([]Sample{drifting(60), drifting(80)})
+
+	c = c.Add(drifting(81))
+	assert.Len(t, c.Samples, 2, "replaces the sample at about the same temperature")
+
+	c = c.Add(drifting(70))
+	assert.Len(t, c.Samples, 3)
+	assert.InDelta(t, 0.02, c.CoefX, 1e-9)
+
+	filename := filepath.Join(t.TempDir(), "cal", "magnet-calibration.json")
+	require.NoError(t, c.Save(filename))
+	loaded, err := LoadCalibration(filename)
+	require.NoError(t, err)
+	assert.InDelta(t, c.CoefX, loaded.CoefX, 1e-12)
+	assert.Len(t, loaded.Samples, 3)
+}
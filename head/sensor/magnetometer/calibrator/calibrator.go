@@ -0,0 +1,85 @@
+package calibrator
+
+import (
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/sensor/magnetometer"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// Turn is what the sensor saw over a full turn
+type Turn struct {
+	Sample   magnetometer.Sample           // the average
+	Readings map[int]*magnetometer.Reading // raw, by step from zero
+}
+
+// Calibrator turns the head a full circle reading the raw field at every step, and hands
+// what it saw to done
+type Calibrator struct {
+	sensor   *magnetometer.Compensated
+	numSteps int
+	pauses   int
+	done     func(turn *Turn, err error)
+
+	readings map[int]*magnetometer.Reading
+	err      error
+}
+
+func New(
+	sensor *magnetometer.Compensated,
+	numSteps int,
+	directionChangePauses int,
+	done func(turn *Turn, err error),
+) *Calibrator {
+	return &Calibrator{
+		sensor:   sensor,
+		numSteps: numSteps,
+		pauses:   directionChangePauses + 1,
+		done:     done,
+		readings: map[int]*magnetometer.Reading{},
+	}
+}
+
+func (c *Calibrator) Name() string {
+	return "magnetometer calibrator"
+}
+
+func (c *Calibrator) Act(pos, target int) (motor.Direction, bool) {
+	if c.pauses > 0 {
+		c.pauses--
+		return motor.NoStep, false
+	}
+
+	if len(c.readings) == c.numSteps {
+		return motor.NoStep, true
+	}
+
+	read, err := c.sensor.Raw()
+	if err != nil {
+		c.err = errors.Wrap(err, "read sensor")
+		return motor.NoStep, true
+	}
+	c.readings[motor.Mod(pos, c.numSteps)] = read
+
+	return motor.Forward, false
+}
+
+func (c *Calibrator) Finish(controller *motor.Controller) {
+	if c.err != nil {
+		c.done(nil, c.err)
+		return
+	}
+	c.done(&Turn{Sample: average(c.readings), Readings: c.readings}, nil)
+}
+
+func average(readings map[int]*magnetometer.Reading) magnetometer.Sample {
+	sample := magnetometer.Sample{At: time.Now()}
+	n := float64(len(readings))
+	for _, r := range readings {
+		sample.Temperature += r.Temperature / n
+		sample.Bx += r.Bx / n
+		sample.By += r.By / n
+		sample.Bz += r.Bz / n
+	}
+	return sample
+}
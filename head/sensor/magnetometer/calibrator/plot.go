@@ -0,0 +1,62 @@
+package calibrator
+
+import (
+	"bytes"
+	"github.com/minor-industries/theheads/head/sensor/magnetometer"
+	"github.com/pkg/errors"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+)
+
+// Plot shows each axis of the samples against temperature, with the fitted drift
+func Plot(c *magnetometer.Calibration) ([]byte, error) {
+	p := plot.New()
+
+	p.Title.Text = "Magnet Sensor Calibration"
+	p.X.Label.Text = "temperature (F)"
+	p.Y.Label.Text = "B (mT)"
+
+	var lines []interface{}
+	for _, axis := range []struct {
+		name         string
+		offset, coef float64
+		get          func(s magnetometer.Sample) float64
+	}{
+		{"x", c.OffsetX, c.CoefX, func(s magnetometer.Sample) float64 { return s.Bx }},
+		{"y", c.OffsetY, c.CoefY, func(s magnetometer.Sample) float64 { return s.By }},
+		{"z", c.OffsetZ, c.CoefZ, func(s magnetometer.Sample) float64 { return s.Bz }},
+	} {
+		var samples, fit plotter.XYs
+		lo, hi := c.Reference, c.Reference
+		for _, s := range c.Samples {
+			samples = append(samples, plotter.XY{X: s.Temperature, Y: axis.get(s)})
+			if s.Temperature < lo {
+				lo = s.Temperature
+			}
+			if s.Temperature > hi {
+				hi = s.Temperature
+			}
+		}
+		for _, t := range []float64{lo, hi} {
+			fit = append(fit, plotter.XY{X: t, Y: axis.offset + axis.coef*(t-c.Reference)})
+		}
+		lines = append(lines, axis.name, samples, axis.name+" fit", fit)
+	}
+
+	if err := plotutil.AddLinePoints(p, lines...); err != nil {
+		return nil, errors.Wrap(err, "add line points")
+	}
+
+	w, err := p.WriterTo(6*vg.Inch, 4*vg.Inch, "svg")
+	if err != nil {
+		return nil, errors.Wrap(err, "writer to")
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := w.WriteTo(buf); err != nil {
+		return nil, errors.Wrap(err, "write to")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package magnetometer
+
+import (
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Estimator keeps estimating where the head is from the field map, so the latest
+// estimate is at hand without reading the sensor
+type Estimator struct {
+	logger *zap.Logger
+	sensor *Compensated
+	period time.Duration
+
+	lock   sync.Mutex
+	latest *Estimate
+	at     time.Time
+}
+
+func NewEstimator(logger *zap.Logger, sensor *Compensated, period time.Duration) *Estimator {
+	return &Estimator{
+		logger: logger,
+		sensor: sensor,
+		period: period,
+	}
+}
+
+func (e *Estimator) Run() {
+	ticker := time.NewTicker(e.period)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		est, err := e.sensor.Estimate()
+		if err != nil && err != ErrNoFieldMap {
+			e.logger.Debug("error estimating position", zap.Error(err))
+		}
+
+		e.lock.Lock()
+		if err == nil {
+			e.latest, e.at = &est, time.Now()
+		} else {
+			e.latest = nil
+		}
+		e.lock.Unlock()
+	}
+}
+
+// Latest is nil until there's a field map to estimate from
+func (e *Estimator) Latest() (*Estimate, time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.latest, e.at
+}
@@ -0,0 +1,91 @@
+package magnetometer
+
+import (
+	"github.com/pkg/errors"
+	"math"
+)
+
+var ErrNoFieldMap = errors.New("no field map")
+
+// about the sensor's resolution; differences smaller than this don't tell steps apart
+const noise = 0.1 // mT
+
+type Field struct {
+	Bx, By, Bz float64 // milliteslas
+}
+
+// FieldMap is the raw field the sensor saw at each step of a full turn, from zero
+type FieldMap struct {
+	Temperature float64 // Fahrenheit, when the turn was made
+	Points      []Field // indexed by step
+}
+
+func NewFieldMap(numSteps int, temperature float64, readings map[int]*Reading) (*FieldMap, error) {
+	m := &FieldMap{Temperature: temperature, Points: make([]Field, numSteps)}
+	for pos := range m.Points {
+		read, ok := readings[pos]
+		if !ok {
+			return nil, errors.Errorf("no reading at step %d", pos)
+		}
+		m.Points[pos] = Field{Bx: read.Bx, By: read.By, Bz: read.Bz}
+	}
+	return m, nil
+}
+
+// Estimate is where a reading puts the head. Confidence runs from 0, when somewhere else
+// in the turn matches about as well, to 1 when nowhere else comes close.
+type Estimate struct {
+	Pos        int
+	Confidence float64
+}
+
+// Estimate finds the step whose field is closest to the (compensated) reading. Steps
+// either side of the best match look alike anyway, so the rival it's judged against is
+// the best match from the rest of the turn.
+func (c *Calibration) Estimate(read *Reading) (Estimate, error) {
+	m := c.Map
+	if m == nil || len(m.Points) == 0 {
+		return Estimate{}, ErrNoFieldMap
+	}
+	n := len(m.Points)
+
+	// the map is kept raw, so it's compensated the same way as the reading
+	drift := c.Apply(&Reading{Temperature: m.Temperature})
+
+	dist := make([]float64, n)
+	best := 0
+	for pos, p := range m.Points {
+		dx := p.Bx + drift.Bx - read.Bx
+		dy := p.By + drift.By - read.By
+		dz := p.Bz + drift.Bz - read.Bz
+		dist[pos] = math.Sqrt(dx*dx + dy*dy + dz*dz)
+		if dist[pos] < dist[best] {
+			best = pos
+		}
+	}
+
+	neighborhood := n / 20
+	if neighborhood < 2 {
+		neighborhood = 2
+	}
+
+	rival := math.Inf(1)
+	for pos, d := range dist {
+		away := pos - best
+		if away < 0 {
+			away = -away
+		}
+		if away > n/2 {
+			away = n - away
+		}
+		if away > neighborhood && d < rival {
+			rival = d
+		}
+	}
+
+	est := Estimate{Pos: best}
+	if !math.IsInf(rival, 1) {
+		est.Confidence = 1 - (dist[best]+noise)/(rival+noise)
+	}
+	return est, nil
+}
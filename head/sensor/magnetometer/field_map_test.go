@@ -0,0 +1,73 @@
+package magnetometer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+const mapSteps = 200
+
+// field is strong near zero and falls away to the background elsewhere in the turn
+func field(pos int, temperature float64) *Reading {
+	rel := float64(pos)
+	if rel > mapSteps/2 {
+		rel -= mapSteps
+	}
+	peak := math.Exp(-rel * rel / (2 * 8 * 8))
+	return &Reading{
+		Bx:          0.2 + 0.02*(temperature-70),
+		By:          2 * rel / 8 * peak,
+		Bz:          -0.4 + 5*peak,
+		Temperature: temperature,
+	}
+}
+
+func fieldMap(t *testing.T, temperature float64) *FieldMap {
+	readings := map[int]*Reading{}
+	for pos := 0; pos < mapSteps; pos++ {
+		readings[pos] = field(pos, temperature)
+	}
+	m, err := NewFieldMap(mapSteps, temperature, readings)
+	require.NoError(t, err)
+	return m
+}
+
+func TestCalibration_Estimate(t *testing.T) {
+	c := &Calibration{Map: fieldMap(t, 70)}
+
+	for _, pos := range []int{0, 5, 190} {
+		est, err := c.Estimate(field(pos, 70))
+		require.NoError(t, err)
+		assert.Equal(t, pos, est.Pos)
+		assert.Greater(t, est.Confidence, 0.9)
+	}
+
+	// far from the magnet everywhere looks the same
+	est, err := c.Estimate(field(100, 70))
+	require.NoError(t, err)
+	assert.Less(t, est.Confidence, 0.1)
+}
+
+func TestCalibration_EstimateDrift(t *testing.T) {
+	c := Fit([]Sample{
+		{Temperature: 60, Bx: 0.2 + 0.02*(60-70)},
+		{Temperature: 80, Bx: 0.2 + 0.02*(80-70)},
+	})
+	c.Map = fieldMap(t, 60)
+
+	// mapped cold and read hot
+	est, err := c.Estimate(c.Apply(field(4, 85)))
+	require.NoError(t, err)
+	assert.Equal(t, 4, est.Pos)
+	assert.Greater(t, est.Confidence, 0.9)
+}
+
+func TestCalibration_EstimateNoMap(t *testing.T) {
+	_, err := (&Calibration{}).Estimate(field(0, 70))
+	assert.Equal(t, ErrNoFieldMap, err)
+
+	_, err = NewFieldMap(mapSteps, 70, map[int]*Reading{0: field(0, 70)})
+	assert.Error(t, err)
+}
@@ -0,0 +1,176 @@
+package zero_detector
+
+import (
+	"github.com/minor-industries/platform/common/broker"
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/messages"
+	"github.com/minor-industries/theheads/head/sensor/magnetometer"
+	"go.uber.org/zap"
+	"math"
+	"time"
+)
+
+const monitorPeriod = 5 * time.Millisecond
+
+type positioner interface {
+	GetState() *motor.State
+	ShiftZero(steps int)
+}
+
+// Monitor keeps checking the head's position after it has been zeroed. Every time the
+// head passes through zero it looks for the peak of the magnet's signature, which should
+// be right at zero; if it isn't the stepper has skipped steps.
+type Monitor struct {
+	logger     *zap.Logger
+	sensor     magnetometer.Sensor
+	controller positioner
+	broker     *broker.Broker
+	name       string
+
+	numSteps  int
+	window    int // steps either side of zero
+	tolerance int
+	rezero    bool
+
+	samples map[int]float64
+}
+
+func NewMonitor(
+	logger *zap.Logger,
+	sensor magnetometer.Sensor,
+	controller positioner,
+	broker *broker.Broker,
+	name string,
+	numSteps int,
+	window int,
+	tolerance int,
+	rezero bool,
+) *Monitor {
+	if window < 3 || window >= numSteps/2 {
+		panic("drift window must be at least 3 steps and under half a turn")
+	}
+
+	setupMetrics()
+
+	return &Monitor{
+		logger:     logger,
+		sensor:     sensor,
+		controller: controller,
+		broker:     broker,
+		name:       name,
+		numSteps:   numSteps,
+		window:     window,
+		tolerance:  tolerance,
+		rezero:     rezero,
+		samples:    map[int]float64{},
+	}
+}
+
+func (m *Monitor) Run() {
+	ticker := time.NewTicker(monitorPeriod)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.tick()
+	}
+}
+
+func (m *Monitor) tick() {
+	state := m.controller.GetState()
+	if !state.Zeroed || state.ActorName == Name {
+		m.samples = map[int]float64{}
+		return
+	}
+
+	var value float64
+	if m.near(state.Pos) {
+		read, err := m.sensor.Read()
+		if err != nil {
+			m.logger.Debug("error reading magnet sensor", zap.Error(err))
+			return
+		}
+		value = signature(read)
+	}
+
+	drift, ok := m.observe(state.Pos, value)
+	if !ok {
+		return
+	}
+
+	gStepDelta.Set(float64(drift))
+	if abs(drift) <= m.tolerance {
+		return
+	}
+
+	m.logger.Warn("head has drifted", zap.Int("steps", drift), zap.Bool("rezero", m.rezero))
+	if m.rezero {
+		m.controller.ShiftZero(drift)
+	}
+
+	m.broker.Publish(&messages.PositionDrift{
+		HeadName:  m.name,
+		Steps:     drift,
+		Corrected: m.rezero,
+	})
+}
+
+// offset is the shortest way from zero to pos, negative behind it
+func (m *Monitor) offset(pos int) int {
+	rel := Mod(pos, m.numSteps)
+	if rel > m.numSteps/2 {
+		rel -= m.numSteps
+	}
+	return rel
+}
+
+func (m *Monitor) near(pos int) bool {
+	return abs(m.offset(pos)) <= m.window
+}
+
+// observe records the signature at pos, and once the head has left the window around
+// zero having been all the way across it, returns where the signature peaked
+func (m *Monitor) observe(pos int, value float64) (int, bool) {
+	if m.near(pos) {
+		m.samples[m.offset(pos)] = value
+		return 0, false
+	}
+
+	if len(m.samples) == 0 {
+		return 0, false
+	}
+
+	samples := m.samples
+	m.samples = map[int]float64{}
+
+	// only a complete pass says where the peak is; the head may have turned back halfway
+	edge := m.window - 2
+	var low, high bool
+	for rel := range samples {
+		low = low || rel <= -edge
+		high = high || rel >= edge
+	}
+	if !low || !high {
+		return 0, false
+	}
+
+	peak, best := 0, math.Inf(-1)
+	for rel, v := range samples {
+		if v > best || (v == best && abs(rel) < abs(peak)) {
+			peak, best = rel, v
+		}
+	}
+
+	if abs(peak) >= edge {
+		// the real peak could be outside the window, so this isn't a measurement
+		return 0, false
+	}
+
+	return peak, true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
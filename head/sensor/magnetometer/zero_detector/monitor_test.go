@@ -0,0 +1,88 @@
+package zero_detector
+
+import (
+	"github.com/minor-industries/platform/common/broker"
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/sensor/magnetometer"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+type fakeController struct {
+	state *motor.State
+	shift int
+}
+
+func (f *fakeController) GetState() *motor.State { return f.state }
+func (f *fakeController) ShiftZero(steps int)    { f.shift += steps }
+
+// pass sweeps the head from from to to around zero, with the signature peaking at peak
+func pass(m *Monitor, from, to, peak int) (int, bool) {
+	step := 1
+	if to < from {
+		step = -1
+	}
+	for pos := from; pos != to+step; pos += step {
+		value := -float64(abs(m.offset(pos) - peak))
+		if drift, ok := m.observe(pos, value); ok {
+			return drift, true
+		}
+	}
+	return 0, false
+}
+
+func TestMonitor_Observe(t *testing.T) {
+	controller := &fakeController{}
+	m := NewMonitor(zap.NewNop(), nil, controller, broker.NewBroker(), "head-01", 200, 10, 2, true)
+
+	drift, ok := pass(m, -20, 20, 0)
+	assert.True(t, ok)
+	assert.Equal(t, 0, drift)
+
+	drift, ok = pass(m, 220, 180, 4) // backwards, a turn later
+	assert.True(t, ok)
+	assert.Equal(t, 4, drift)
+
+	// turning back halfway doesn't count
+	_, ok = pass(m, -20, 2, 0)
+	assert.False(t, ok)
+	_, ok = pass(m, 1, -20, 0)
+	assert.False(t, ok)
+
+	// nor does a peak at the edge of the window
+	_, ok = pass(m, -20, 20, 9)
+	assert.False(t, ok)
+}
+
+func TestMonitor_Rezero(t *testing.T) {
+	controller := &fakeController{state: &motor.State{Zeroed: true, ActorName: "Seeker"}}
+	m := NewMonitor(zap.NewNop(), &fakeSensor{controller: controller, peak: 5}, controller, broker.NewBroker(), "head-01", 200, 10, 2, true)
+
+	for pos := -30; pos <= 30; pos++ {
+		controller.state.Pos = pos
+		m.tick()
+	}
+	assert.Equal(t, 5, controller.shift)
+
+	// not zeroed yet, so nothing to check against
+	controller.state.Zeroed = false
+	controller.shift = 0
+	for pos := -30; pos <= 30; pos++ {
+		controller.state.Pos = pos
+		m.tick()
+	}
+	assert.Equal(t, 0, controller.shift)
+}
+
+type fakeSensor struct {
+	controller *fakeController
+	peak       int
+}
+
+func (f *fakeSensor) HasHardware() bool { return true }
+
+func (f *fakeSensor) Read() (*magnetometer.Reading, error) {
+	d := float64(abs(f.controller.state.Pos - f.peak))
+	return &magnetometer.Reading{Bz: 10 - d, By: 1}, nil
+}
@@ -22,6 +22,19 @@ import (
 var metricsOnce sync.Once
 var gStepDelta prometheus.Gauge
 
+// Name is what the controller reports while the detector is running
+const Name = "magnetometer zero detector"
+
+func setupMetrics() {
+	metricsOnce.Do(func() {
+		gStepDelta = metrics.SimpleGauge(
+			prometheus.DefaultRegisterer,
+			"head",
+			"zero_detector_step_delta",
+		)
+	})
+}
+
 type ZeroDetector struct {
 	logger                *zap.Logger
 	sensor                magnetometer.Sensor
@@ -35,6 +48,7 @@ type ZeroDetector struct {
 	getSteps              func() int
 	gStepDelta            prometheus.Gauge
 	svgCallback           func(string, []byte)
+	err                   error // set before ch is closed
 }
 
 type result struct {
@@ -57,13 +71,7 @@ func NewZeroDetector(
 		findDirection = motor.Backward
 	}
 
-	metricsOnce.Do(func() {
-		gStepDelta = metrics.SimpleGauge(
-			prometheus.DefaultRegisterer,
-			"head",
-			"zero_detector_step_delta",
-		)
-	})
+	setupMetrics()
 
 	d := &ZeroDetector{
 		logger:                logger,
@@ -84,7 +92,7 @@ func NewZeroDetector(
 
 func (d *ZeroDetector) Act(pos, target int) (direction motor.Direction, done bool) {
 	direction, done = d.getStep()
-	if done {
+	if done && d.err == nil {
 		actual := int(d.stepped.Load())
 		predicted := int(d.predicted.Load())
 
@@ -109,10 +117,14 @@ func (d *ZeroDetector) Act(pos, target int) (direction motor.Direction, done boo
 }
 
 func (d *ZeroDetector) Name() string {
-	return "magnetometer zero detector"
+	return Name
 }
 
 func (d *ZeroDetector) Finish(controller *motor.Controller) {
+	if d.err != nil {
+		controller.ZeroFailed(d.err.Error())
+		return
+	}
 	controller.SetCurrentPositionAsZero()
 }
 
@@ -151,8 +163,7 @@ func (d *ZeroDetector) find(
 		}
 		points[i] = read
 
-		val := math.Abs(read.Bz) - math.Abs(read.By)
-		values = append(values, val)
+		values = append(values, signature(read))
 
 		d.ch <- result{direction: findDirection}
 	}
@@ -214,12 +225,14 @@ func (d *ZeroDetector) controller() {
 		idx = Mod(idx, d.numSteps)
 		if err != nil {
 			d.logger.Error("find error", zap.Error(err))
+			d.err = errors.Wrap(err, "find")
 			d.done()
 			return
 		}
 
 		if err := d.seek("seek1.svg", idx-stepsBack); err != nil {
 			d.logger.Error("seek error", zap.Error(err))
+			d.err = errors.Wrap(err, "seek")
 			d.done()
 			return
 		}
@@ -232,6 +245,7 @@ func (d *ZeroDetector) controller() {
 		idx, err := d.find("fine.svg", NumSlowSteps, motor.Forward, true)
 		if err != nil {
 			d.logger.Error("find error", zap.Error(err))
+			d.err = errors.Wrap(err, "find")
 			d.done()
 			return
 		}
@@ -239,12 +253,14 @@ func (d *ZeroDetector) controller() {
 
 		if err := d.seek("seek2.svg", -NumSlowSteps); err != nil {
 			d.logger.Error("seek error", zap.Error(err))
+			d.err = errors.Wrap(err, "seek")
 			d.done()
 			return
 		}
 
 		if err := d.seek("seek3.svg", idx); err != nil {
 			d.logger.Error("seek error", zap.Error(err))
+			d.err = errors.Wrap(err, "seek")
 			d.done()
 			return
 		}
@@ -263,6 +279,10 @@ func (d *ZeroDetector) plot(
 	name string,
 	points []*magnetometer.Reading,
 ) {
+	if len(points) == 0 {
+		return // nothing to plot when the seek was already there
+	}
+
 	p := plot.New()
 
 	p.Title.Text = fmt.Sprintf("Magnet Sensor (%s)", name)
@@ -289,7 +309,7 @@ func (d *ZeroDetector) plot(
 
 		ptsVal = append(ptsVal, plotter.XY{
 			X: float64(i),
-			Y: math.Abs(pt.Bz) - math.Abs(pt.By),
+			Y: signature(pt),
 		})
 	}
 
@@ -312,6 +332,11 @@ func (d *ZeroDetector) plot(
 	d.svgCallback(name, buf.Bytes())
 }
 
+// signature peaks where the magnet passes the sensor, which is where zero is
+func signature(read *magnetometer.Reading) float64 {
+	return math.Abs(read.Bz) - math.Abs(read.By)
+}
+
 func maxIdx(values []float64) (float64, int) {
 	max := math.SmallestNonzeroFloat64
 	idx := -1
@@ -0,0 +1,159 @@
+package head
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/sensor/magnetometer"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// savedState is what the head remembers about its position across restarts
+type savedState struct {
+	Pos       int
+	ZeroedAt  time.Time // zero if the head wasn't zeroed
+	Released  bool
+	Uncertain bool
+	SavedAt   time.Time
+}
+
+func saveState(filename string, controller *motor.Controller) error {
+	state := controller.GetState()
+	saved := &savedState{
+		Pos:       state.Pos,
+		Released:  state.Released,
+		Uncertain: state.Uncertain,
+		SavedAt:   time.Now(),
+	}
+	if state.Zeroed {
+		saved.ZeroedAt = state.ZeroedAt
+	}
+
+	content, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return errors.Wrap(err, "mkdir")
+	}
+
+	// write then rename, so a crash mid-write leaves the previous state
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return errors.Wrap(err, "write")
+	}
+	return errors.Wrap(os.Rename(tmp, filename), "rename")
+}
+
+func loadState(filename string) (*savedState, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "read")
+	}
+
+	saved := &savedState{}
+	if err := json.Unmarshal(content, saved); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	return saved, nil
+}
+
+// stale says why the saved zero can't be trusted any more, if it can't
+func (s *savedState) stale(now time.Time, maxAge time.Duration) string {
+	switch {
+	case s.ZeroedAt.IsZero():
+		return "not zeroed"
+	case now.Sub(s.SavedAt) > maxAge:
+		return "too old"
+	case s.Released:
+		return "motor was released"
+	case s.Uncertain:
+		return "position was uncertain"
+	default:
+		return ""
+	}
+}
+
+func restoreState(
+	logger *zap.Logger,
+	filename string,
+	maxAge time.Duration,
+	controller *motor.Controller,
+) {
+	logger = logger.With(zap.String("filename", filename))
+
+	saved, err := loadState(filename)
+	if err != nil {
+		logger.Info("unable to load head state", zap.Error(err))
+		return
+	}
+
+	// the position is still the best guess when stale, but the head needs zeroing again
+	zeroedAt := saved.ZeroedAt
+	if reason := saved.stale(time.Now(), maxAge); reason != "" {
+		logger.Info("head state is stale", zap.String("reason", reason))
+		zeroedAt = time.Time{}
+	}
+
+	controller.Restore(saved.Pos, zeroedAt)
+	logger.Info(
+		"restored head state",
+		zap.Int("pos", saved.Pos),
+		zap.Bool("zeroed", !zeroedAt.IsZero()),
+	)
+}
+
+// estimateState places a head that doesn't know where it is from the magnet sensor's
+// field map, which saves a search for zero when the estimate is good enough
+func estimateState(
+	logger *zap.Logger,
+	sensor *magnetometer.Compensated,
+	minConfidence float64,
+	controller *motor.Controller,
+) {
+	est, err := sensor.Estimate()
+	if err != nil {
+		logger.Info("unable to estimate head position", zap.Error(err))
+		return
+	}
+
+	logger = logger.With(zap.Int("pos", est.Pos), zap.Float64("confidence", est.Confidence))
+	if est.Confidence < minConfidence {
+		logger.Info("head position estimate isn't confident enough")
+		return
+	}
+
+	controller.Restore(est.Pos, time.Now())
+	logger.Info("estimated head position")
+}
+
+// keepState saves the head's state every period, and once more when ctx is done
+func keepState(
+	ctx context.Context,
+	logger *zap.Logger,
+	filename string,
+	period time.Duration,
+	controller *motor.Controller,
+) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+		}
+
+		if err := saveState(filename, controller); err != nil {
+			logger.Error("error saving head state", zap.Error(err))
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package head
+
+import (
+	"context"
+	"github.com/minor-industries/platform/common/broker"
+	"github.com/minor-industries/theheads/head/motor"
+	"github.com/minor-industries/theheads/head/motor/fake_stepper"
+	"github.com/minor-industries/theheads/head/motor/idle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newController() *motor.Controller {
+	return motor.NewController(
+		zap.NewNop(),
+		fake_stepper.NewMotor(),
+		broker.NewBroker(),
+		&motor.Cfg{
+			NumSteps:              200,
+			DirectionChangePauses: 3,
+			StepSpeed:             30,
+			MaxVelocity:           40,
+			Acceleration:          80,
+			VelocityLimit:         100,
+			AccelerationLimit:     200,
+		},
+		"head-01",
+		idle.New(),
+	)
+}
+
+func TestState(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state", "head.json")
+
+	before := newController()
+	before.SetCurrentPositionAsZero()
+	require.NoError(t, before.Step(motor.Forward))
+	require.NoError(t, saveState(filename, before))
+
+	after := newController()
+	restoreState(zap.NewNop(), filename, time.Hour, after)
+	state := after.GetState()
+	assert.Equal(t, 1, state.Pos)
+	assert.Equal(t, 1, state.Target)
+	assert.True(t, state.Zeroed)
+
+	// too old to trust the zero, but the position is still the best guess
+	after = newController()
+	restoreState(zap.NewNop(), filename, 0, after)
+	state = after.GetState()
+	assert.Equal(t, 1, state.Pos)
+	assert.False(t, state.Zeroed)
+
+	now := time.Now()
+	for reason, saved := range map[string]*savedState{
+		"":                       {ZeroedAt: now, SavedAt: now},
+		"not zeroed":             {SavedAt: now},
+		"too old":                {ZeroedAt: now, SavedAt: now.Add(-2 * time.Hour)},
+		"motor was released":     {ZeroedAt: now, SavedAt: now, Released: true},
+		"position was uncertain": {ZeroedAt: now, SavedAt: now, Uncertain: true},
+	} {
+		assert.Equal(t, reason, saved.stale(now, time.Hour))
+	}
+}
+
+func TestKeepStateSavesOnStop(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "head.json")
+	controller := newController()
+	controller.SetCurrentPositionAsZero()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// returns instead of exiting, having saved once more
+	keepState(ctx, zap.NewNop(), filename, time.Hour, controller)
+
+	saved, err := loadState(filename)
+	require.NoError(t, err)
+	assert.False(t, saved.ZeroedAt.IsZero())
+}
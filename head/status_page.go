@@ -0,0 +1,121 @@
+package head
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/minor-industries/protobuf/gen/go/heads"
+	"github.com/minor-industries/theheads/head/fe"
+	headgrpc "github.com/minor-industries/theheads/head/grpc"
+	"github.com/minor-industries/theheads/head/voices"
+	cmap "github.com/orcaman/concurrent-map/v2"
+	"go.uber.org/zap"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// setupStatusPage serves a page for looking at and poking the head from a browser, on
+// top of the same calls boss and heads-cli make
+func setupStatusPage(
+	logger *zap.Logger,
+	router *gin.Engine,
+	instance string,
+	h *headgrpc.Handler,
+	v *voices.Server,
+	svgs cmap.ConcurrentMap[string, []byte],
+) {
+	router.SetHTMLTemplate(template.Must(template.New("").ParseFS(fe.FS, "*.html")))
+
+	templateArgs := map[string]any{
+		"Title": instance,
+	}
+
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(200, "index.html", templateArgs)
+	})
+
+	api := router.Group("/api")
+
+	respond := func(c *gin.Context, result any, err error) {
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(200, result)
+	}
+
+	api.GET("/state", func(c *gin.Context) {
+		result, err := h.Status(c, &heads.Empty{})
+		respond(c, result, err)
+	})
+
+	api.GET("/magnet", func(c *gin.Context) {
+		result, err := h.ReadMagnetSensor(c, &heads.Empty{})
+		respond(c, result, err)
+	})
+
+	api.GET("/voices", func(c *gin.Context) {
+		result, err := v.Status(c, &heads.Empty{})
+		respond(c, result, err)
+	})
+
+	api.GET("/media", func(c *gin.Context) {
+		result, err := v.ListMedia(c, &heads.ListMediaIn{})
+		respond(c, result, err)
+	})
+
+	api.GET("/plots", func(c *gin.Context) {
+		names := svgs.Keys()
+		sort.Strings(names)
+		c.JSON(200, names)
+	})
+
+	api.POST("/find-zero", func(c *gin.Context) {
+		result, err := h.FindZero(c, &heads.Empty{})
+		respond(c, result, err)
+	})
+
+	api.POST("/motor-off", func(c *gin.Context) {
+		result, err := h.MotorOff(c, &heads.Empty{})
+		respond(c, result, err)
+	})
+
+	// jog turns the head by degrees from where it is, taking over from whatever actor
+	// was running
+	api.POST("/jog", func(c *gin.Context) {
+		degrees, err := strconv.ParseFloat(c.Query("degrees"), 64)
+		if err != nil {
+			c.String(http.StatusBadRequest, "bad degrees: %s", err)
+			return
+		}
+
+		state, err := h.SetActor(c, &heads.SetActorIn{Actor: "Seeker"})
+		if err != nil {
+			respond(c, nil, err)
+			return
+		}
+		result, err := h.SetTarget(c, &heads.SetTargetIn{Theta: state.Rotation + degrees})
+		respond(c, result, err)
+	})
+
+	// play returns straight away rather than once the clip has finished
+	api.POST("/play", func(c *gin.Context) {
+		sound := c.Query("sound")
+		if sound == "" {
+			c.String(http.StatusBadRequest, "missing sound")
+			return
+		}
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			if _, err := v.Play(ctx, &heads.PlayIn{Sound: sound}); err != nil {
+				logger.Info("error playing from status page", zap.String("sound", sound), zap.Error(err))
+			}
+		}()
+		c.JSON(http.StatusAccepted, gin.H{"sound": sound})
+	})
+}
@@ -0,0 +1,43 @@
+package alsa
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"os"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Card finds the number of a card given by number or by id, e.g. Device
+func Card(card string) (int, error) {
+	n, err := strconv.Atoi(card)
+	if err == nil {
+		return n, nil
+	}
+
+	link, err := os.Readlink("/proc/asound/" + card)
+	if err != nil {
+		return 0, errors.Wrap(err, "find card")
+	}
+	n, err = strconv.Atoi(strings.TrimPrefix(link, "card"))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected card link %s", link)
+	}
+	return n, nil
+}
+
+// IOC encodes an ioctl number the asm-generic way, as on arm and x86. typ is 'A' for
+// PCM devices and 'U' for controls.
+func IOC(dir, typ, nr, size uintptr) uintptr {
+	return dir<<30 | size<<16 | typ<<8 | nr
+}
+
+func Ioctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), req, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
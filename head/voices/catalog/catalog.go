@@ -0,0 +1,267 @@
+package catalog
+
+import (
+	"github.com/minor-industries/theheads/head/voices/playback"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerSize = 64 * 1024 // enough to find the data chunk in any of our recordings
+	tagsExt    = ".tags"
+)
+
+var (
+	ErrNotFound    = errors.New("media not found")
+	ErrOutsideRoot = errors.New("path escapes the media root")
+)
+
+type Entry struct {
+	Name     string // relative to the media root
+	Path     string
+	Duration time.Duration
+	Tags     []string
+
+	modTime time.Time
+	size    int64
+	tagsMod time.Time
+}
+
+func (e *Entry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Catalog indexes the wav files under a media root. Each foo.wav can have a foo.tags
+// sidecar listing tags such as "scream" or "whisper", separated by spaces or newlines.
+type Catalog struct {
+	logger *zap.Logger
+	root   string
+
+	lock    sync.Mutex
+	entries map[string]*Entry
+}
+
+func New(logger *zap.Logger, root string) *Catalog {
+	return &Catalog{
+		logger:  logger,
+		root:    root,
+		entries: map[string]*Entry{},
+	}
+}
+
+// Watch rescans the media root periodically, re-reading only the files that changed
+func (c *Catalog) Watch(period time.Duration) {
+	for {
+		if err := c.Scan(); err != nil {
+			c.logger.Error("error scanning media", zap.Error(err))
+		}
+		time.Sleep(period)
+	}
+}
+
+func (c *Catalog) Scan() error {
+	c.lock.Lock()
+	old := c.entries
+	c.lock.Unlock()
+
+	entries := map[string]*Entry{}
+	err := filepath.WalkDir(c.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".wav") {
+			return nil
+		}
+
+		name, err := filepath.Rel(c.root, path)
+		if err != nil {
+			return err
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			if err := c.inside(path); err != nil {
+				c.logger.Warn("skipping media", zap.String("path", path), zap.Error(err))
+				return nil
+			}
+		}
+
+		entry, err := c.load(name, path, old[name])
+		if err != nil {
+			c.logger.Warn("skipping media", zap.String("path", path), zap.Error(err))
+			return nil
+		}
+		entries[name] = entry
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "walk")
+	}
+
+	c.lock.Lock()
+	c.entries = entries
+	c.lock.Unlock()
+	return nil
+}
+
+func (c *Catalog) load(name, path string, prev *Entry) (*Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tagsPath := strings.TrimSuffix(path, ".wav") + tagsExt
+	var tagsMod time.Time
+	if tagsInfo, err := os.Stat(tagsPath); err == nil {
+		tagsMod = tagsInfo.ModTime()
+	}
+
+	if prev != nil &&
+		prev.modTime.Equal(info.ModTime()) &&
+		prev.size == info.Size() &&
+		prev.tagsMod.Equal(tagsMod) {
+		return prev, nil
+	}
+
+	duration, err := wavDuration(path, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		Name:     name,
+		Path:     path,
+		Duration: duration,
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+		tagsMod:  tagsMod,
+	}
+
+	if !tagsMod.IsZero() {
+		content, err := os.ReadFile(tagsPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "read tags")
+		}
+		entry.Tags = strings.Fields(strings.ToLower(string(content)))
+	}
+
+	return entry, nil
+}
+
+func wavDuration(path string, fileSize int64) (time.Duration, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = fp.Close()
+	}()
+
+	header := make([]byte, headerSize)
+	n, err := io.ReadFull(fp, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, errors.Wrap(err, "read header")
+	}
+
+	format, offset, size, err := playback.ParseWAVHeader(header[:n])
+	if err != nil {
+		return 0, err
+	}
+
+	if remaining := int(fileSize) - offset; size > remaining || size == 0 {
+		size = remaining
+	}
+
+	return playback.Duration(format, size), nil
+}
+
+// Resolve finds a sound by its name relative to the media root, refusing anything that
+// would reach outside the root
+func (c *Catalog) Resolve(name string) (*Entry, error) {
+	clean := filepath.Clean(name)
+	if filepath.IsAbs(clean) || escapes(clean) {
+		return nil, ErrOutsideRoot
+	}
+
+	if err := c.inside(filepath.Join(c.root, clean)); err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	entry, ok := c.entries[clean]
+	c.lock.Unlock()
+	if ok {
+		return entry, nil
+	}
+
+	// new since the last scan
+	if err := c.Scan(); err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if entry, ok := c.entries[clean]; ok {
+		return entry, nil
+	}
+	return nil, ErrNotFound
+}
+
+// inside checks that path, once symlinks are followed, is still under the media root
+func (c *Catalog) inside(path string) error {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return ErrNotFound
+	}
+	realRoot, err := filepath.EvalSymlinks(c.root)
+	if err != nil {
+		return ErrNotFound
+	}
+	if rel, err := filepath.Rel(realRoot, real); err != nil || escapes(rel) {
+		return ErrOutsideRoot
+	}
+	return nil
+}
+
+func escapes(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// List returns the entries with the given tag, or all of them if tag is empty
+func (c *Catalog) List(tag string) []*Entry {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var result []*Entry
+	for _, entry := range c.entries {
+		if tag == "" || entry.HasTag(tag) {
+			result = append(result, entry)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (c *Catalog) Random(tag string) (*Entry, error) {
+	entries := c.List(tag)
+	if len(entries) == 0 {
+		return nil, ErrNotFound
+	}
+	return entries[rand.Intn(len(entries))], nil
+}
@@ -0,0 +1,97 @@
+package catalog
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// wav writes a mono 16 bit 8kHz file of the given length
+func wav(t *testing.T, path string, d time.Duration) {
+	pcm := make([]byte, int(d.Seconds()*8000)*2)
+
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:], 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:], 1)
+	binary.LittleEndian.PutUint32(fmtChunk[4:], 8000)
+	binary.LittleEndian.PutUint16(fmtChunk[14:], 16)
+
+	content := []byte("RIFF\x00\x00\x00\x00WAVE")
+	for _, chunk := range []struct {
+		id   string
+		body []byte
+	}{{"fmt ", fmtChunk}, {"data", pcm}} {
+		size := make([]byte, 4)
+		binary.LittleEndian.PutUint32(size, uint32(len(chunk.body)))
+		content = append(content, chunk.id...)
+		content = append(content, size...)
+		content = append(content, chunk.body...)
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, content, 0o644))
+}
+
+func TestCatalog(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "media")
+
+	wav(t, filepath.Join(root, "screams", "01.wav"), 2*time.Second)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "screams", "01.tags"), []byte("Scream\nloud"), 0o644))
+	wav(t, filepath.Join(root, "hello.wav"), 500*time.Millisecond)
+	wav(t, filepath.Join(dir, "secret.wav"), time.Second)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "notes.txt"), []byte("not media"), 0o644))
+
+	c := New(zap.NewNop(), root)
+	require.NoError(t, c.Scan())
+
+	all := c.List("")
+	require.Len(t, all, 2)
+	assert.Equal(t, "hello.wav", all[0].Name)
+	assert.Equal(t, 500*time.Millisecond, all[0].Duration)
+	assert.Equal(t, "screams/01.wav", all[1].Name)
+	assert.Equal(t, 2*time.Second, all[1].Duration)
+	assert.Equal(t, []string{"scream", "loud"}, all[1].Tags)
+
+	e, err := c.Random("scream")
+	require.NoError(t, err)
+	assert.Equal(t, "screams/01.wav", e.Name)
+
+	_, err = c.Random("whisper")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	e, err = c.Resolve("screams/../hello.wav")
+	require.NoError(t, err)
+	assert.Equal(t, "hello.wav", e.Name)
+
+	for _, name := range []string{"../secret.wav", "screams/../../secret.wav", filepath.Join(dir, "secret.wav")} {
+		_, err = c.Resolve(name)
+		assert.ErrorIs(t, err, ErrOutsideRoot, name)
+	}
+
+	require.NoError(t, os.Symlink(filepath.Join(dir, "secret.wav"), filepath.Join(root, "link.wav")))
+	_, err = c.Resolve("link.wav")
+	assert.ErrorIs(t, err, ErrOutsideRoot)
+	require.NoError(t, c.Scan())
+	assert.Len(t, c.List(""), 2)
+
+	_, err = c.Resolve("missing.wav")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// new files turn up without waiting for the next scan, and tag changes are picked up
+	wav(t, filepath.Join(root, "whisper.wav"), time.Second)
+	e, err = c.Resolve("whisper.wav")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, e.Duration)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "whisper.tags"), []byte("whisper"), 0o644))
+	require.NoError(t, c.Scan())
+	e, err = c.Random("whisper")
+	require.NoError(t, err)
+	assert.Equal(t, "whisper.wav", e.Name)
+}
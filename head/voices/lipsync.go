@@ -0,0 +1,73 @@
+package voices
+
+import (
+	"context"
+	"github.com/minor-industries/protobuf/gen/go/heads"
+	"github.com/minor-industries/theheads/head/voices/playback"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+const envelopeFrame = 40 * time.Millisecond // the leds update period
+
+// lipSync sends the envelope of each clip to the leds service on the same stand as it
+// starts, so the strip pulses along with the voice. The envelope is worked out when the
+// clip is loaded.
+type lipSync struct {
+	logger *zap.Logger
+	client heads.LedsClient
+	ch     chan *heads.EnvelopeIn
+}
+
+func newLipSync(logger *zap.Logger, addr string) *lipSync {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		panic(err)
+	}
+
+	l := &lipSync{
+		logger: logger,
+		client: heads.NewLedsClient(conn),
+		ch:     make(chan *heads.EnvelopeIn, 4),
+	}
+	go l.run()
+	return l
+}
+
+func (l *lipSync) Started(clip *playback.Clip, at time.Time) {
+	l.send(&heads.EnvelopeIn{
+		Start:  timestamppb.New(at),
+		Frame:  durationpb.New(envelopeFrame),
+		Levels: clip.Envelope,
+	})
+}
+
+func (l *lipSync) Finished(clip *playback.Clip, err error) {
+	if err != nil {
+		// cut off, so stop pulsing
+		l.send(&heads.EnvelopeIn{})
+	}
+}
+
+func (l *lipSync) send(in *heads.EnvelopeIn) {
+	select {
+	case l.ch <- in:
+	default:
+		l.logger.Warn("dropping led envelope")
+	}
+}
+
+func (l *lipSync) run() {
+	for in := range l.ch {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := l.client.Envelope(ctx, in)
+		cancel()
+		if err != nil {
+			l.logger.Warn("error sending led envelope", zap.Error(err))
+		}
+	}
+}
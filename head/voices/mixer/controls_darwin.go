@@ -0,0 +1,26 @@
+package mixer
+
+import (
+	"github.com/pkg/errors"
+)
+
+// cardControls has nothing to control on darwin; use the fake mixer there
+type cardControls struct{}
+
+func newCardControls(card string) controls {
+	return cardControls{}
+}
+
+var errNoMixer = errors.New("no device mixer on darwin")
+
+func (cardControls) info(name string) (*controlInfo, error) {
+	return nil, errNoMixer
+}
+
+func (cardControls) read(name string, count int) ([]int, error) {
+	return nil, errNoMixer
+}
+
+func (cardControls) write(name string, values []int) error {
+	return errNoMixer
+}
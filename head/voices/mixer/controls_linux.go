@@ -0,0 +1,163 @@
+package mixer
+
+import (
+	"fmt"
+	"github.com/minor-industries/theheads/head/voices/alsa"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"unsafe"
+)
+
+// cardControls reads and writes mixer elements through the card's control device with
+// the kernel's ALSA ioctls
+type cardControls struct {
+	card string
+}
+
+func newCardControls(card string) controls {
+	return &cardControls{card: card}
+}
+
+func (c *cardControls) open() (int, error) {
+	n, err := alsa.Card(c.card)
+	if err != nil {
+		return -1, err
+	}
+
+	path := fmt.Sprintf("/dev/snd/controlC%d", n)
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return -1, errors.Wrap(err, path)
+	}
+	return fd, nil
+}
+
+func (c *cardControls) info(name string) (*controlInfo, error) {
+	fd, err := c.open()
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	in := &elemInfo{}
+	if err := in.id.setName(name); err != nil {
+		return nil, err
+	}
+	if err := ctlIoctl(fd, ioctlElemInfo, unsafe.Pointer(in)); err != nil {
+		return nil, err
+	}
+	if in.typ != elemTypeBoolean && in.typ != elemTypeInteger {
+		return nil, fmt.Errorf("%s isn't an integer control", name)
+	}
+
+	info := &controlInfo{min: in.value[0], max: in.value[1], count: int(in.count)}
+	if in.access&accessTLVRead == 0 {
+		return info, nil
+	}
+
+	// numid and the buffer's length, then the TLV data
+	buf := make([]uint32, 2+64)
+	buf[0], buf[1] = in.id.numid, uint32(4*(len(buf)-2))
+	if err := ctlIoctl(fd, ioctlTLVRead, unsafe.Pointer(&buf[0])); err != nil {
+		return nil, errors.Wrap(err, "read dB scale")
+	}
+	info.tlv = buf[2:]
+
+	return info, nil
+}
+
+func (c *cardControls) read(name string, count int) ([]int, error) {
+	fd, err := c.open()
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	v := &elemValue{}
+	if err := v.id.setName(name); err != nil {
+		return nil, err
+	}
+	if err := ctlIoctl(fd, ioctlElemRead, unsafe.Pointer(v)); err != nil {
+		return nil, err
+	}
+	return append([]int(nil), v.value[:count]...), nil
+}
+
+func (c *cardControls) write(name string, values []int) error {
+	fd, err := c.open()
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	v := &elemValue{}
+	if err := v.id.setName(name); err != nil {
+		return err
+	}
+	copy(v.value[:], values)
+	return ctlIoctl(fd, ioctlElemWrite, unsafe.Pointer(v))
+}
+
+// ctlIoctl reports an element that isn't there as errNoControl
+func ctlIoctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	err := alsa.Ioctl(fd, req, arg)
+	if err == unix.ENOENT {
+		return errNoControl
+	}
+	return err
+}
+
+// What follows mirrors include/uapi/sound/asound.h. C longs are as wide as Go's int on
+// every architecture the heads run on.
+
+const (
+	ifaceMixer = 2
+
+	elemTypeBoolean = 1
+	elemTypeInteger = 2
+
+	accessTLVRead = 1 << 4
+)
+
+type elemID struct {
+	numid     uint32
+	iface     int32
+	device    uint32
+	subdevice uint32
+	name      [44]byte
+	index     uint32
+}
+
+func (id *elemID) setName(name string) error {
+	if len(name) >= len(id.name) {
+		return fmt.Errorf("control name too long: %s", name)
+	}
+	id.iface = ifaceMixer
+	copy(id.name[:], name)
+	return nil
+}
+
+type elemInfo struct {
+	id     elemID
+	typ    int32
+	access uint32
+	count  uint32
+	owner  int32
+	value  [128 / unsafe.Sizeof(int(0))]int // min, max and step for integers
+	_      [64]byte
+}
+
+type elemValue struct {
+	id       elemID
+	indirect uint32
+	_        [valuePad]uint32
+	value    [128]int
+	_        [128]byte
+}
+
+var (
+	ioctlElemInfo  = alsa.IOC(3, 'U', 0x11, unsafe.Sizeof(elemInfo{}))
+	ioctlElemRead  = alsa.IOC(3, 'U', 0x12, unsafe.Sizeof(elemValue{}))
+	ioctlElemWrite = alsa.IOC(3, 'U', 0x13, unsafe.Sizeof(elemValue{}))
+	ioctlTLVRead   = alsa.IOC(3, 'U', 0x1a, 8)
+)
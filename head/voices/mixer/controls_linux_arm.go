@@ -0,0 +1,4 @@
+package mixer
+
+// the value union holds long longs, which C aligns to 8 bytes on arm but Go only to 4
+const valuePad = 1
@@ -0,0 +1,5 @@
+//go:build linux && !arm
+
+package mixer
+
+const valuePad = 0
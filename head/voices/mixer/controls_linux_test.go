@@ -0,0 +1,39 @@
+package mixer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+// The expected values come from include/uapi/sound/asound.h built with gcc for each
+// architecture the heads run on. Run with GOARCH=arm, arm64, 386 and amd64.
+func TestALSALayout(t *testing.T) {
+	var v elemValue
+	var info elemInfo
+
+	assert.Equal(t, uintptr(64), unsafe.Sizeof(elemID{}))
+	assert.Equal(t, uintptr(16), unsafe.Offsetof(elemID{}.name))
+	assert.Equal(t, uintptr(272), unsafe.Sizeof(info))
+	assert.Equal(t, uintptr(80), unsafe.Offsetof(info.value))
+	assert.Equal(t, uintptr(0xc1105511), ioctlElemInfo)
+	assert.Equal(t, uintptr(0xc008551a), ioctlTLVRead)
+
+	type layout struct {
+		value, size, read, write uintptr
+	}
+	expected := map[string]layout{
+		"amd64": {value: 72, size: 1224, read: 0xc4c85512, write: 0xc4c85513},
+		"arm64": {value: 72, size: 1224, read: 0xc4c85512, write: 0xc4c85513},
+		"arm":   {value: 72, size: 712, read: 0xc2c85512, write: 0xc2c85513},
+		"386":   {value: 68, size: 708, read: 0xc2c45512, write: 0xc2c45513},
+	}[runtime.GOARCH]
+
+	assert.Equal(t, expected, layout{
+		value: unsafe.Offsetof(v.value),
+		size:  unsafe.Sizeof(v),
+		read:  ioctlElemRead,
+		write: ioctlElemWrite,
+	})
+}
@@ -0,0 +1,126 @@
+package mixer
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"sort"
+)
+
+var errNoControl = errors.New("no such control")
+
+type controlInfo struct {
+	min, max int
+	count    int      // values, one per channel
+	tlv      []uint32 // dB scale, for volumes
+}
+
+// controls are a card's mixer elements, looked up by their full name, e.g. Speaker
+// Playback Volume
+type controls interface {
+	info(name string) (*controlInfo, error)
+	read(name string, count int) ([]int, error)
+	write(name string, values []int) error
+}
+
+// deviceMixer works the way amixer's simple controls do: the volume and the mute switch
+// for control are the elements named after it
+type deviceMixer struct {
+	controls controls
+	control  string
+}
+
+func newDeviceMixer(c controls, control string) *deviceMixer {
+	return &deviceMixer{controls: c, control: control}
+}
+
+// find returns the first of the control's elements with the given suffixes that exists
+func (m *deviceMixer) find(suffixes ...string) (string, *controlInfo, error) {
+	for _, suffix := range suffixes {
+		name := m.control + " " + suffix
+		info, err := m.controls.info(name)
+		if err == errNoControl {
+			continue
+		}
+		if err != nil {
+			return "", nil, errors.Wrap(err, name)
+		}
+		return name, info, nil
+	}
+	return "", nil, errNoControl
+}
+
+func (m *deviceMixer) volume() (string, *controlInfo, dbScale, error) {
+	name, info, err := m.find("Playback Volume", "Volume")
+	if err == errNoControl {
+		return "", nil, nil, fmt.Errorf("no volume control named %s", m.control)
+	}
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	scale, err := parseDBScale(info.tlv, info.min, info.max)
+	if err != nil {
+		return "", nil, nil, errors.Wrap(err, name)
+	}
+	return name, info, scale, nil
+}
+
+func (m *deviceMixer) Get() (*Level, error) {
+	name, info, scale, err := m.volume()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := m.controls.read(name, info.count)
+	if err != nil {
+		return nil, errors.Wrap(err, name)
+	}
+
+	// the first channel, as amixer shows it
+	raw := values[0]
+	level := &Level{
+		DB:      scale(raw),
+		Percent: int(math.Round(float64(raw-info.min) * 100 / float64(info.max-info.min))),
+	}
+
+	switchName, switchInfo, err := m.find("Playback Switch", "Switch")
+	switch {
+	case err == errNoControl:
+		return level, nil
+	case err != nil:
+		return nil, err
+	}
+
+	switches, err := m.controls.read(switchName, switchInfo.count)
+	if err != nil {
+		return nil, errors.Wrap(err, switchName)
+	}
+	level.Muted = switches[0] == 0
+
+	return level, nil
+}
+
+func (m *deviceMixer) SetDB(db float64) error {
+	name, info, scale, err := m.volume()
+	if err != nil {
+		return err
+	}
+
+	// the raw value closest to db, ties going to the quieter one
+	n := info.max - info.min + 1
+	i := sort.Search(n, func(i int) bool { return scale(info.min+i) >= db })
+	raw := info.min + i
+	switch {
+	case i == n:
+		raw = info.max
+	case i > 0 && db-scale(raw-1) <= scale(raw)-db:
+		raw--
+	}
+
+	values := make([]int, info.count)
+	for i := range values {
+		values[i] = raw
+	}
+	return errors.Wrap(m.controls.write(name, values), name)
+}
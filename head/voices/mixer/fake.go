@@ -0,0 +1,62 @@
+package mixer
+
+import (
+	"sync"
+)
+
+// fakeControls is a card with a single stereo volume control and its mute switch, raw
+// values 0 to 37 spread evenly over -60dB to 0dB, so it reads back the way amixer would
+// show it
+type fakeControls struct {
+	volume   string
+	switches string
+
+	lock   sync.Mutex
+	values map[string][]int
+}
+
+func newFakeControls(control string) *fakeControls {
+	volume, switches := control+" Playback Volume", control+" Playback Switch"
+	return &fakeControls{
+		volume:   volume,
+		switches: switches,
+		values: map[string][]int{
+			volume:   {37, 37},
+			switches: {1, 1},
+		},
+	}
+}
+
+func (f *fakeControls) info(name string) (*controlInfo, error) {
+	switch name {
+	case f.volume:
+		minDB := -6000
+		return &controlInfo{min: 0, max: 37, count: 2, tlv: []uint32{tlvDBMinMax, 8, uint32(minDB), 0}}, nil
+	case f.switches:
+		return &controlInfo{min: 0, max: 1, count: 2}, nil
+	default:
+		return nil, errNoControl
+	}
+}
+
+func (f *fakeControls) read(name string, count int) ([]int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	values, ok := f.values[name]
+	if !ok {
+		return nil, errNoControl
+	}
+	return append([]int(nil), values[:count]...), nil
+}
+
+func (f *fakeControls) write(name string, values []int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, ok := f.values[name]; !ok {
+		return errNoControl
+	}
+	f.values[name] = append([]int(nil), values...)
+	return nil
+}
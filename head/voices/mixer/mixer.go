@@ -0,0 +1,30 @@
+package mixer
+
+import (
+	"fmt"
+)
+
+type Level struct {
+	DB      float64
+	Percent int
+	Muted   bool
+}
+
+// Mixer is the playback volume control of a sound card
+type Mixer interface {
+	// SetDB sets the volume, clamped to what the control supports
+	SetDB(db float64) error
+	Get() (*Level, error)
+}
+
+// New returns the card's own "device" mixer, or an in-memory "fake" one
+func New(kind string, card string, control string) (Mixer, error) {
+	switch kind {
+	case "device":
+		return newDeviceMixer(newCardControls(card), control), nil
+	case "fake":
+		return newDeviceMixer(newFakeControls(control), control), nil
+	default:
+		return nil, fmt.Errorf("unknown mixer %q", kind)
+	}
+}
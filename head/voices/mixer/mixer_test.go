@@ -0,0 +1,53 @@
+package mixer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMixer(t *testing.T) {
+	m, err := New("fake", "Device", "Speaker")
+	require.NoError(t, err)
+
+	level, err := m.Get()
+	require.NoError(t, err)
+	assert.Equal(t, &Level{DB: 0, Percent: 100}, level)
+
+	require.NoError(t, m.SetDB(-30))
+	level, err = m.Get()
+	require.NoError(t, err)
+	assert.InDelta(t, -30, level.DB, 1.7) // one step of the control
+	assert.Equal(t, 49, level.Percent, "halfway between two steps goes to the quieter one")
+
+	// clamped to the control's range
+	require.NoError(t, m.SetDB(6))
+	level, err = m.Get()
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, level.DB)
+
+	require.NoError(t, m.SetDB(-100))
+	level, err = m.Get()
+	require.NoError(t, err)
+	assert.Equal(t, &Level{DB: -60, Percent: 0}, level)
+
+	_, err = newDeviceMixer(newFakeControls("Speaker"), "Headphone").Get()
+	assert.EqualError(t, err, "no volume control named Headphone")
+}
+
+func TestMuted(t *testing.T) {
+	c := newFakeControls("PCM")
+	m := newDeviceMixer(c, "PCM")
+	require.NoError(t, c.write("PCM Playback Switch", []int{0, 0}))
+
+	level, err := m.Get()
+	require.NoError(t, err)
+	assert.True(t, level.Muted)
+
+	// controls without a switch are never muted
+	delete(c.values, "PCM Playback Switch")
+	c.switches = ""
+	level, err = m.Get()
+	require.NoError(t, err)
+	assert.False(t, level.Muted)
+}
@@ -0,0 +1,141 @@
+package mixer
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+)
+
+// TLV types from include/uapi/sound/tlv.h
+const (
+	tlvContainer    = 0
+	tlvDBScale      = 1
+	tlvDBLinear     = 2
+	tlvDBRange      = 3
+	tlvDBMinMax     = 4
+	tlvDBMinMaxMute = 5
+)
+
+// muteDB is what alsa-lib reports for a muted level
+const muteDB = -99999.99
+
+// dbScale gives the level in dB for a control's raw value
+type dbScale func(raw int) float64
+
+// parseDBScale reads the dB description of a control whose raw values run from min to
+// max. Levels in TLV data are in hundredths of a dB.
+func parseDBScale(tlv []uint32, min, max int) (dbScale, error) {
+	if len(tlv) < 2 {
+		return nil, errors.New("no dB scale")
+	}
+	typ, data := tlv[0], tlv[2:]
+	if n := int(tlv[1]+3) / 4; n <= len(data) {
+		data = data[:n]
+	} else {
+		return nil, errors.New("short TLV")
+	}
+
+	db := func(v uint32) float64 {
+		return float64(int32(v)) / 100
+	}
+	fraction := func(raw int) float64 {
+		if max == min {
+			return 1
+		}
+		return float64(raw-min) / float64(max-min)
+	}
+
+	switch typ {
+	case tlvContainer:
+		// the first dB description inside
+		for len(data) >= 2 {
+			n := 2 + int(data[1]+3)/4
+			if n > len(data) {
+				return nil, errors.New("short TLV")
+			}
+			if scale, err := parseDBScale(data[:n], min, max); err == nil {
+				return scale, nil
+			}
+			data = data[n:]
+		}
+		return nil, errors.New("no dB scale")
+
+	case tlvDBScale:
+		if len(data) < 2 {
+			return nil, errors.New("short TLV")
+		}
+		dbMin, step, mute := db(data[0]), float64(data[1]&0xffff)/100, data[1]&0x10000 != 0
+		return func(raw int) float64 {
+			if mute && raw == min {
+				return muteDB
+			}
+			return dbMin + float64(raw-min)*step
+		}, nil
+
+	case tlvDBMinMax, tlvDBMinMaxMute:
+		if len(data) < 2 {
+			return nil, errors.New("short TLV")
+		}
+		dbMin, dbMax := db(data[0]), db(data[1])
+		return func(raw int) float64 {
+			if typ == tlvDBMinMaxMute && raw == min {
+				return muteDB
+			}
+			return dbMin + fraction(raw)*(dbMax-dbMin)
+		}, nil
+
+	case tlvDBLinear:
+		if len(data) < 2 {
+			return nil, errors.New("short TLV")
+		}
+		// linear in amplitude rather than in dB
+		dbMin, dbMax := db(data[0]), db(data[1])
+		lMin, lMax := math.Pow(10, dbMin/20), math.Pow(10, dbMax/20)
+		return func(raw int) float64 {
+			if raw <= min {
+				return dbMin
+			}
+			return 20 * math.Log10(lMin+fraction(raw)*(lMax-lMin))
+		}, nil
+
+	case tlvDBRange:
+		// runs of raw values, each with its own dB description
+		type part struct {
+			min, max int
+			scale    dbScale
+		}
+		var parts []part
+		for len(data) >= 4 {
+			n := 4 + int(data[3]+3)/4
+			if n > len(data) {
+				return nil, errors.New("short TLV")
+			}
+			p := part{min: int(int32(data[0])), max: int(int32(data[1]))}
+			scale, err := parseDBScale(data[2:n], p.min, p.max)
+			if err != nil {
+				return nil, err
+			}
+			p.scale = scale
+			parts = append(parts, p)
+			data = data[n:]
+		}
+		if len(parts) == 0 {
+			return nil, errors.New("empty dB range")
+		}
+		return func(raw int) float64 {
+			for _, p := range parts {
+				if raw >= p.min && raw <= p.max {
+					return p.scale(raw)
+				}
+			}
+			if raw < parts[0].min {
+				return parts[0].scale(parts[0].min)
+			}
+			last := parts[len(parts)-1]
+			return last.scale(last.max)
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown TLV type %d", typ)
+	}
+}
@@ -0,0 +1,56 @@
+package mixer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func centiDB(db int) uint32 {
+	return uint32(int32(db))
+}
+
+func TestParseDBScale(t *testing.T) {
+	check := func(tlv []uint32, min, max int, expected map[int]float64) {
+		scale, err := parseDBScale(tlv, min, max)
+		require.NoError(t, err)
+		for raw, db := range expected {
+			assert.InDelta(t, db, scale(raw), 0.01, "raw %d", raw)
+		}
+	}
+
+	// -50dB in steps of 0.5dB, the lowest value muting
+	check([]uint32{tlvDBScale, 8, centiDB(-5000), 50 | 0x10000}, 0, 100, map[int]float64{
+		0: muteDB, 1: -49.5, 100: 0,
+	})
+
+	// USB audio devices: -60dB to 0dB spread over the raw range
+	check([]uint32{tlvDBMinMax, 8, centiDB(-6000), 0}, -120, 0, map[int]float64{
+		-120: -60, -60: -30, 0: 0,
+	})
+	check([]uint32{tlvDBMinMaxMute, 8, centiDB(-6000), 0}, 0, 60, map[int]float64{
+		0: muteDB, 30: -30,
+	})
+
+	// linear in amplitude: halfway is -6dB
+	check([]uint32{tlvDBLinear, 8, centiDB(-9999999), 0}, 0, 100, map[int]float64{
+		50: -6.02, 100: 0,
+	})
+
+	// a range inside a container, as the hda drivers describe their controls
+	check([]uint32{
+		tlvContainer, 56,
+		tlvDBRange, 48,
+		0, 9, tlvDBScale, 8, centiDB(-4000), 200,
+		10, 20, tlvDBScale, 8, centiDB(-2000), 100,
+	}, 0, 20, map[int]float64{
+		0: -40, 9: -22, 10: -20, 20: -10, 25: -10,
+	})
+
+	_, err := parseDBScale(nil, 0, 10)
+	assert.Error(t, err)
+	_, err = parseDBScale([]uint32{tlvDBScale, 8, 0}, 0, 10)
+	assert.Error(t, err)
+	_, err = parseDBScale([]uint32{99, 0}, 0, 10)
+	assert.Error(t, err)
+}
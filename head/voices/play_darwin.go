@@ -1,5 +0,0 @@
-package voices
-
-func execPlay(deviceName string) []string {
-	return []string{"afplay"}
-}
@@ -0,0 +1,62 @@
+package playback
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// convert turns pcm into the device format, which is 16 or 32 bit integer samples.
+// Rates are changed by linear interpolation, which is plenty for voices. Extra channels
+// are mixed down and missing ones are copied from the channels the clip has.
+func convert(from Format, pcm []byte, to Format) []byte {
+	if from == to {
+		return pcm
+	}
+
+	bytesPerSample := from.BitsPerSample / 8
+	inFrames := len(pcm) / from.bytesPerFrame()
+	if inFrames == 0 {
+		return nil
+	}
+
+	read := func(frame, ch int) float64 {
+		base := frame * from.Channels
+		if to.Channels == 1 && from.Channels > 1 {
+			var sum float64
+			for c := 0; c < from.Channels; c++ {
+				sum += from.sample(pcm[(base+c)*bytesPerSample:])
+			}
+			return sum / float64(from.Channels)
+		}
+		return from.sample(pcm[(base+ch%from.Channels)*bytesPerSample:])
+	}
+
+	outFrames := int(int64(inFrames) * int64(to.SampleRate) / int64(from.SampleRate))
+	step := float64(from.SampleRate) / float64(to.SampleRate)
+	out := make([]byte, outFrames*to.bytesPerFrame())
+	pos := 0
+
+	for i := 0; i < outFrames; i++ {
+		t := float64(i) * step
+		j := int(t)
+		next := j + 1
+		if next >= inFrames {
+			next = inFrames - 1
+		}
+
+		for c := 0; c < to.Channels; c++ {
+			a, b := read(j, c), read(next, c)
+			x := math.Max(-1, math.Min(1, a+(b-a)*(t-float64(j))))
+
+			if to.BitsPerSample == 32 {
+				binary.LittleEndian.PutUint32(out[pos:], uint32(int32(x*math.MaxInt32)))
+				pos += 4
+			} else {
+				binary.LittleEndian.PutUint16(out[pos:], uint16(int16(x*math.MaxInt16)))
+				pos += 2
+			}
+		}
+	}
+
+	return out
+}
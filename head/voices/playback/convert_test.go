@@ -0,0 +1,86 @@
+package playback
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func s16(samples ...int16) []byte {
+	b := make([]byte, 2*len(samples))
+	for i, x := range samples {
+		binary.LittleEndian.PutUint16(b[2*i:], uint16(x))
+	}
+	return b
+}
+
+func readS16(b []byte) []float64 {
+	var out []float64
+	for i := 0; i+2 <= len(b); i += 2 {
+		out = append(out, float64(int16(binary.LittleEndian.Uint16(b[i:]))))
+	}
+	return out
+}
+
+func TestConvert(t *testing.T) {
+	// 8kHz mono ramp to 16kHz stereo: every other frame is interpolated
+	from := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 8}
+	to := Format{SampleRate: 16000, Channels: 2, BitsPerSample: 16}
+
+	out := convert(from, []byte{128, 192, 255}, to)
+	require.Len(t, out, 6*4)
+
+	var left []float64
+	for i := 0; i < len(out); i += 4 {
+		assert.Equal(t, out[i:i+2], out[i+2:i+4], "channels should match")
+		left = append(left, float64(int16(binary.LittleEndian.Uint16(out[i:]))))
+	}
+	assert.InDeltaSlice(t, []float64{0, 8191, 16383, 24447, 32511, 32511}, left, 1)
+
+	pcm := []byte{1, 2, 3, 4}
+	assert.Equal(t, pcm, convert(testFormat, pcm, testFormat))
+}
+
+func TestConvertDownmix(t *testing.T) {
+	from := Format{SampleRate: 8000, Channels: 2, BitsPerSample: 16}
+	to := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+
+	out := convert(from, s16(16384, 0, -16384, -16384, 32767, -32767), to)
+	assert.InDeltaSlice(t, []float64{8191, -16383, 0}, readS16(out), 1)
+}
+
+func TestConvertDownsample(t *testing.T) {
+	from := Format{SampleRate: 48000, Channels: 1, BitsPerSample: 16}
+	to := Format{SampleRate: 16000, Channels: 1, BitsPerSample: 16}
+
+	// every third frame survives
+	out := convert(from, s16(0, 1, 2, 300, 301, 302, 600, 601, 602), to)
+	assert.InDeltaSlice(t, []float64{0, 300, 600}, readS16(out), 1)
+
+	// a frame too short to make a single output frame
+	assert.Empty(t, convert(from, s16(5), Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}))
+	assert.Empty(t, convert(from, nil, to))
+}
+
+func TestConvertTo32Bit(t *testing.T) {
+	to := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 32}
+
+	out := convert(testFormat, s16(16384, -32768), to)
+	require.Len(t, out, 8)
+	assert.InDelta(t, math.MaxInt32/2, float64(int32(binary.LittleEndian.Uint32(out))), 1<<16)
+	assert.Equal(t, int32(-math.MaxInt32), int32(binary.LittleEndian.Uint32(out[4:])))
+}
+
+func TestConvertFloat(t *testing.T) {
+	from := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 32, Float: true}
+
+	pcm := make([]byte, 12)
+	for i, x := range []float32{0.5, -0.25, 2} {
+		binary.LittleEndian.PutUint32(pcm[4*i:], math.Float32bits(x))
+	}
+
+	// out of range samples are clipped
+	assert.InDeltaSlice(t, []float64{16383, -8191, 32767}, readS16(convert(from, pcm, testFormat)), 1)
+}
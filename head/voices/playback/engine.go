@@ -0,0 +1,276 @@
+package playback
+
+import (
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+var (
+	ErrStopped   = errors.New("stopped")
+	ErrPreempted = errors.New("preempted by a higher priority clip")
+	ErrQueueFull = errors.New("queue full")
+	ErrTooLate   = errors.New("too late to start")
+)
+
+const maxLate = 250 * time.Millisecond
+
+type Request struct {
+	Clip     *Clip
+	Priority int
+	At       time.Time // when to start, zero for as soon as possible
+}
+
+type Job struct {
+	Request
+
+	stopErr error
+	cancel  chan struct{}
+
+	done chan struct{}
+	err  error
+}
+
+// Done is closed once the clip has finished, or was dropped
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+func (j *Job) Err() error {
+	return j.err
+}
+
+// Observer hears when clips actually start coming out of the speaker, and when they
+// finish or are cut off. It's called from the engine's goroutine so shouldn't block.
+type Observer interface {
+	Started(clip *Clip, at time.Time)
+	Finished(clip *Clip, err error)
+}
+
+type Status struct {
+	Playing  bool
+	Clip     string
+	Priority int
+	Position time.Duration
+	Length   time.Duration
+	Queued   []string
+}
+
+// Engine plays one clip at a time from a bounded priority queue. A clip with a higher
+// priority than the one playing cuts it off; equal priorities play in order.
+type Engine struct {
+	logger   *zap.Logger
+	output   Output
+	maxQueue int
+	observer Observer // may be nil
+
+	lock    sync.Mutex
+	queue   []*Job
+	current *Job
+	sink    Sink
+	started time.Time // zero while the current clip waits for its start time
+
+	wake chan struct{}
+}
+
+func NewEngine(logger *zap.Logger, output Output, maxQueue int, observer Observer) *Engine {
+	return &Engine{
+		logger:   logger,
+		output:   output,
+		maxQueue: maxQueue,
+		observer: observer,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+func (e *Engine) Enqueue(req Request) (*Job, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	job := &Job{
+		Request: req,
+		cancel:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if len(e.queue) >= e.maxQueue {
+		// make room by dropping the newest of the lowest priority jobs, if it's below ours
+		lowest := len(e.queue) - 1
+		if e.queue[lowest].Priority >= req.Priority {
+			return nil, ErrQueueFull
+		}
+		finish(e.queue[lowest], ErrPreempted)
+		e.queue = e.queue[:lowest]
+	}
+
+	if e.current != nil && req.Priority > e.current.Priority {
+		e.stopCurrent(ErrPreempted)
+	}
+
+	// the queue is kept sorted by priority, first come first served within a priority
+	idx := len(e.queue)
+	for i, q := range e.queue {
+		if q.Priority < req.Priority {
+			idx = i
+			break
+		}
+	}
+	e.queue = append(e.queue, nil)
+	copy(e.queue[idx+1:], e.queue[idx:])
+	e.queue[idx] = job
+
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// Stop cuts off the current clip, and drops everything queued if clearQueue is set
+func (e *Engine) Stop(clearQueue bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if clearQueue {
+		for _, job := range e.queue {
+			finish(job, ErrStopped)
+		}
+		e.queue = nil
+	}
+
+	e.stopCurrent(ErrStopped)
+}
+
+func (e *Engine) stopCurrent(err error) {
+	job := e.current
+	if job == nil || job.stopErr != nil {
+		return
+	}
+
+	job.stopErr = err
+	close(job.cancel)
+	if e.sink != nil {
+		e.sink.Close()
+	}
+}
+
+func (e *Engine) Status() Status {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	s := Status{}
+	for _, job := range e.queue {
+		s.Queued = append(s.Queued, job.Clip.Name)
+	}
+
+	if job := e.current; job != nil {
+		s.Playing = !e.started.IsZero()
+		s.Clip = job.Clip.Name
+		s.Priority = job.Priority
+		s.Length = job.Clip.Duration()
+		if s.Playing {
+			s.Position = time.Since(e.started)
+			if s.Position > s.Length {
+				s.Position = s.Length
+			}
+		}
+	}
+
+	return s
+}
+
+func (e *Engine) Run() {
+	for {
+		job := e.next()
+		err := e.play(job)
+		if err != nil && err != ErrStopped && err != ErrPreempted {
+			e.logger.Error("error playing", zap.String("clip", job.Clip.Name), zap.Error(err))
+		}
+
+		e.lock.Lock()
+		if job.stopErr != nil {
+			err = job.stopErr
+		}
+		started := !e.started.IsZero()
+		e.current = nil
+		e.sink = nil
+		e.started = time.Time{}
+		e.lock.Unlock()
+
+		if started && e.observer != nil {
+			e.observer.Finished(job.Clip, err)
+		}
+		finish(job, err)
+	}
+}
+
+func (e *Engine) next() *Job {
+	for {
+		e.lock.Lock()
+		if len(e.queue) > 0 {
+			job := e.queue[0]
+			e.queue = e.queue[1:]
+			e.current = job
+			e.lock.Unlock()
+			return job
+		}
+		e.lock.Unlock()
+
+		<-e.wake
+	}
+}
+
+func (e *Engine) play(job *Job) error {
+	sink, err := e.output.Open(job.Clip.Format)
+	if err != nil {
+		return errors.Wrap(err, "open output")
+	}
+
+	e.lock.Lock()
+	if job.stopErr != nil {
+		e.lock.Unlock()
+		sink.Close()
+		return job.stopErr
+	}
+	e.sink = sink
+	e.lock.Unlock()
+
+	if !job.At.IsZero() {
+		wait := time.Until(job.At)
+		if wait < -maxLate {
+			sink.Close()
+			return ErrTooLate
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-job.cancel:
+			return job.stopErr
+		}
+	}
+
+	e.lock.Lock()
+	e.started = time.Now()
+	started := e.started
+	e.lock.Unlock()
+
+	if !job.At.IsZero() {
+		e.logger.Debug(
+			"starting scheduled clip",
+			zap.String("clip", job.Clip.Name),
+			zap.Duration("late", started.Sub(job.At)),
+		)
+	}
+	if e.observer != nil {
+		e.observer.Started(job.Clip, started)
+	}
+
+	return sink.Play(job.Clip.PCM)
+}
+
+func finish(job *Job, err error) {
+	job.err = err
+	close(job.done)
+}
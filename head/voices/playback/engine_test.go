@@ -0,0 +1,142 @@
+package playback
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+var testFormat = Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+
+func clip(name string, d time.Duration) *Clip {
+	n := int(d.Seconds()*float64(testFormat.SampleRate)) * 2
+	return &Clip{Name: name, Format: testFormat, PCM: make([]byte, n)}
+}
+
+func wait(t *testing.T, job *Job) error {
+	select {
+	case <-job.Done():
+		return job.Err()
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for job")
+		return nil
+	}
+}
+
+func TestQueueAndPreempt(t *testing.T) {
+	e := NewEngine(zap.NewNop(), &NullOutput{Realtime: true}, 2, nil)
+	go e.Run()
+
+	chatter, err := e.Enqueue(Request{Clip: clip("chatter", time.Second)})
+	require.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	s := e.Status()
+	assert.True(t, s.Playing)
+	assert.Equal(t, "chatter", s.Clip)
+	assert.Equal(t, time.Second, s.Length)
+	assert.Greater(t, s.Position, time.Duration(0))
+
+	next, err := e.Enqueue(Request{Clip: clip("next", 50*time.Millisecond)})
+	require.NoError(t, err)
+	last, err := e.Enqueue(Request{Clip: clip("last", 50*time.Millisecond)})
+	require.NoError(t, err)
+
+	_, err = e.Enqueue(Request{Clip: clip("dropped", 50*time.Millisecond)})
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	// the scream bumps the newest low priority job out of the full queue, and cuts off chatter
+	scream, err := e.Enqueue(Request{Clip: clip("scream", 50*time.Millisecond), Priority: 10})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, wait(t, last), ErrPreempted)
+	assert.ErrorIs(t, wait(t, chatter), ErrPreempted)
+	assert.NoError(t, wait(t, scream))
+	assert.NoError(t, wait(t, next))
+
+	assert.Equal(t, Status{}, e.Status())
+}
+
+func TestStopAndSchedule(t *testing.T) {
+	e := NewEngine(zap.NewNop(), &NullOutput{Realtime: true}, 4, nil)
+	go e.Run()
+
+	at := time.Now().Add(100 * time.Millisecond)
+	scheduled, err := e.Enqueue(Request{Clip: clip("scheduled", 10*time.Millisecond), At: at})
+	require.NoError(t, err)
+	assert.NoError(t, wait(t, scheduled))
+	assert.False(t, time.Now().Before(at))
+
+	late, err := e.Enqueue(Request{Clip: clip("late", time.Second), At: time.Now().Add(-time.Second)})
+	require.NoError(t, err)
+	assert.ErrorIs(t, wait(t, late), ErrTooLate)
+
+	long, err := e.Enqueue(Request{Clip: clip("long", time.Minute)})
+	require.NoError(t, err)
+	queued, err := e.Enqueue(Request{Clip: clip("queued", time.Minute)})
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, []string{"queued"}, e.Status().Queued)
+
+	e.Stop(true)
+	assert.ErrorIs(t, wait(t, long), ErrStopped)
+	assert.ErrorIs(t, wait(t, queued), ErrStopped)
+}
+
+func TestDecodeWAV(t *testing.T) {
+	pcm := make([]byte, 16000) // one second of 8kHz mono 16 bit
+
+	content := []byte("RIFF\x00\x00\x00\x00WAVE")
+	chunk := func(id string, body []byte) {
+		size := make([]byte, 4)
+		binary.LittleEndian.PutUint32(size, uint32(len(body)))
+		content = append(content, id...)
+		content = append(content, size...)
+		content = append(content, body...)
+	}
+
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:], formatPCM)
+	binary.LittleEndian.PutUint16(fmtChunk[2:], 1)
+	binary.LittleEndian.PutUint32(fmtChunk[4:], 8000)
+	binary.LittleEndian.PutUint16(fmtChunk[14:], 16)
+
+	chunk("fmt ", fmtChunk)
+	chunk("LIST", []byte("odd")) // padded to an even size
+	content = append(content, 0)
+	chunk("data", pcm)
+
+	c, err := DecodeWAV("test.wav", content)
+	require.NoError(t, err)
+	assert.Equal(t, testFormat, c.Format)
+	assert.Equal(t, time.Second, c.Duration())
+
+	_, err = DecodeWAV("bad.wav", []byte("not a wav file"))
+	assert.Error(t, err)
+}
+
+func TestEnvelope(t *testing.T) {
+	c := clip("envelope", 200*time.Millisecond)
+	for i := 0; i < len(c.PCM)/2; i++ {
+		// silent, then a quiet square wave, then a loud one
+		var x int16
+		switch {
+		case i >= 1200:
+			x = 16000
+		case i >= 800:
+			x = 4000
+		}
+		if i%2 == 1 {
+			x = -x
+		}
+		binary.LittleEndian.PutUint16(c.PCM[2*i:], uint16(x))
+	}
+
+	levels := Envelope(c, 50*time.Millisecond)
+	require.Len(t, levels, 4)
+	assert.InDeltaSlice(t, []float32{0, 0, 0.25, 1}, levels, 0.001)
+}
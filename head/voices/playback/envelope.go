@@ -0,0 +1,67 @@
+package playback
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// Envelope is the RMS amplitude of the clip over each frame, scaled so the loudest frame
+// is 1. All channels are mixed together.
+func Envelope(c *Clip, frame time.Duration) []float32 {
+	bytesPerSample := c.Format.BitsPerSample / 8
+	samplesPerFrame := int(frame.Seconds()*float64(c.Format.SampleRate)) * c.Format.Channels
+	if bytesPerSample == 0 || samplesPerFrame == 0 {
+		return nil
+	}
+
+	numSamples := len(c.PCM) / bytesPerSample
+	var levels []float32
+	var peak float64
+
+	for start := 0; start < numSamples; start += samplesPerFrame {
+		end := start + samplesPerFrame
+		if end > numSamples {
+			end = numSamples
+		}
+
+		var sum float64
+		for i := start; i < end; i++ {
+			x := c.Format.sample(c.PCM[i*bytesPerSample:])
+			sum += x * x
+		}
+
+		rms := math.Sqrt(sum / float64(end-start))
+		peak = math.Max(peak, rms)
+		levels = append(levels, float32(rms))
+	}
+
+	if peak > 0 {
+		for i := range levels {
+			levels[i] /= float32(peak)
+		}
+	}
+
+	return levels
+}
+
+// sample decodes the sample at the start of b to the range -1 to 1
+func (f Format) sample(b []byte) float64 {
+	switch {
+	case f.Float && f.BitsPerSample == 32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case f.Float && f.BitsPerSample == 64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	case f.BitsPerSample == 8:
+		return (float64(b[0]) - 128) / 128
+	case f.BitsPerSample == 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case f.BitsPerSample == 24:
+		x := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+		return float64(x) / (1 << 23)
+	case f.BitsPerSample == 32:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,69 @@
+package playback
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Output interface {
+	// Open readies the device for audio in the given format, so that Play starts quickly
+	Open(f Format) (Sink, error)
+}
+
+type Sink interface {
+	// Play blocks until the samples have been played or the sink is closed
+	Play(pcm []byte) error
+	// Close stops playback right away
+	Close()
+}
+
+func NewOutput(name string, card string) (Output, error) {
+	switch name {
+	case "device":
+		return newDeviceOutput(card), nil
+	case "null":
+		return &NullOutput{Realtime: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown audio output: %s", name)
+	}
+}
+
+// NullOutput discards audio. With Realtime set it takes as long as the audio would to
+// play, otherwise it returns right away.
+type NullOutput struct {
+	Realtime bool
+}
+
+func (o *NullOutput) Open(f Format) (Sink, error) {
+	return &nullSink{
+		format: f,
+		closed: make(chan struct{}),
+		rt:     o.Realtime,
+	}, nil
+}
+
+type nullSink struct {
+	format Format
+	rt     bool
+
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (s *nullSink) Play(pcm []byte) error {
+	if !s.rt {
+		return nil
+	}
+
+	select {
+	case <-time.After(Duration(s.format, len(pcm))):
+		return nil
+	case <-s.closed:
+		return ErrStopped
+	}
+}
+
+func (s *nullSink) Close() {
+	s.once.Do(func() { close(s.closed) })
+}
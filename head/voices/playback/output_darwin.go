@@ -0,0 +1,75 @@
+package playback
+
+import (
+	"bytes"
+	"github.com/ebitengine/oto/v3"
+	"github.com/pkg/errors"
+	"sync"
+	"time"
+)
+
+// otoFormat is what every clip is converted to, since oto only allows one context per
+// process and fixes its format
+var otoFormat = Format{SampleRate: 48000, Channels: 2, BitsPerSample: 16}
+
+var (
+	otoOnce    sync.Once
+	otoContext *oto.Context
+	otoErr     error
+)
+
+// deviceOutput plays through the default CoreAudio output with oto
+type deviceOutput struct{}
+
+func newDeviceOutput(card string) Output {
+	return &deviceOutput{}
+}
+
+func (o *deviceOutput) Open(f Format) (Sink, error) {
+	otoOnce.Do(func() {
+		var ready chan struct{}
+		otoContext, ready, otoErr = oto.NewContext(&oto.NewContextOptions{
+			SampleRate:   otoFormat.SampleRate,
+			ChannelCount: otoFormat.Channels,
+			Format:       oto.FormatSignedInt16LE,
+		})
+		if otoErr == nil {
+			<-ready
+		}
+	})
+	if otoErr != nil {
+		return nil, errors.Wrap(otoErr, "oto")
+	}
+
+	return &otoSink{from: f, closed: make(chan struct{})}, nil
+}
+
+type otoSink struct {
+	from Format
+
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (s *otoSink) Play(pcm []byte) error {
+	p := otoContext.NewPlayer(bytes.NewReader(convert(s.from, pcm, otoFormat)))
+	defer func() {
+		_ = p.Close()
+	}()
+
+	p.Play()
+	for p.IsPlaying() {
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-s.closed:
+			p.Pause()
+			return ErrStopped
+		}
+	}
+
+	return errors.Wrap(p.Err(), "oto")
+}
+
+func (s *otoSink) Close() {
+	s.once.Do(func() { close(s.closed) })
+}
@@ -0,0 +1,287 @@
+package playback
+
+import (
+	"fmt"
+	"github.com/minor-industries/theheads/head/voices/alsa"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// deviceOutput writes samples straight to the card's PCM device with the kernel's ALSA
+// ioctls. There's no plug layer in between, so clips are converted to a rate, channel
+// count and sample format the hardware accepts.
+type deviceOutput struct {
+	card string
+}
+
+func newDeviceOutput(card string) Output {
+	return &deviceOutput{card: card}
+}
+
+func (o *deviceOutput) Open(f Format) (Sink, error) {
+	path, err := pcmDevice(o.card)
+	if err != nil {
+		return nil, err
+	}
+
+	// without O_NONBLOCK the open waits for whoever else has the device
+	fd, err := unix.Open(path, unix.O_WRONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	if err := unix.SetNonblock(fd, false); err != nil {
+		_ = unix.Close(fd)
+		return nil, errors.Wrap(err, "set blocking")
+	}
+
+	to, err := configure(fd, f)
+	if err != nil {
+		_ = unix.Close(fd)
+		return nil, errors.Wrap(err, path)
+	}
+
+	return &alsaSink{fd: fd, from: f, to: to}, nil
+}
+
+// pcmDevice finds the playback device for a card given by number or by id, e.g. Device
+func pcmDevice(card string) (string, error) {
+	n, err := alsa.Card(card)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/dev/snd/pcmC%dD0p", n), nil
+}
+
+type alsaSink struct {
+	fd       int
+	from, to Format
+
+	lock    sync.Mutex
+	playing bool
+	closed  bool
+}
+
+func (s *alsaSink) Play(pcm []byte) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return ErrStopped
+	}
+	s.playing = true
+	s.lock.Unlock()
+
+	err := s.write(convert(s.from, pcm, s.to))
+	if err == nil {
+		err = retry(func() error { return alsa.Ioctl(s.fd, ioctlDrain, nil) })
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.playing = false
+	_ = unix.Close(s.fd)
+	s.fd = -1
+
+	if s.closed {
+		return ErrStopped
+	}
+	return errors.Wrap(err, "alsa")
+}
+
+func (s *alsaSink) write(data []byte) error {
+	frameBytes := s.to.bytesPerFrame()
+	for len(data) >= frameBytes {
+		x := xferi{buf: unsafe.Pointer(&data[0]), frames: uint(len(data) / frameBytes)}
+		err := alsa.Ioctl(s.fd, ioctlWriteiFrames, unsafe.Pointer(&x))
+		runtime.KeepAlive(data)
+
+		switch {
+		case err == unix.EINTR || err == unix.EAGAIN:
+			continue
+		case err == unix.EPIPE:
+			// underrun, start again from where we are
+			if err := alsa.Ioctl(s.fd, ioctlPrepare, nil); err != nil {
+				return errors.Wrap(err, "prepare")
+			}
+			continue
+		case err != nil:
+			return errors.Wrap(err, "write")
+		}
+
+		data = data[x.result*frameBytes:]
+	}
+	return nil
+}
+
+func (s *alsaSink) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed || s.fd < 0 {
+		s.closed = true
+		return
+	}
+	s.closed = true
+
+	if s.playing {
+		// wakes up Play, which closes the device
+		_ = alsa.Ioctl(s.fd, ioctlDrop, nil)
+		return
+	}
+	_ = unix.Close(s.fd)
+	s.fd = -1
+}
+
+func retry(f func() error) error {
+	for {
+		if err := f(); err != unix.EINTR {
+			return err
+		}
+	}
+}
+
+// What follows mirrors include/uapi/sound/asound.h. Go lays these structs out the same
+// way as C, including snd_pcm_uframes_t being as wide as uint.
+
+const (
+	paramAccess     = 0
+	paramFormat     = 1
+	paramSubformat  = 2
+	paramChannels   = 10
+	paramRate       = 11
+	paramPeriodTime = 12
+
+	firstInterval = 8
+
+	accessRWInterleaved = 3
+	formatS16LE         = 2
+	formatS32LE         = 10
+)
+
+type mask struct {
+	bits [8]uint32
+}
+
+type interval struct {
+	min, max uint32
+	flags    uint32 // openmin, openmax, integer and empty bitfields
+}
+
+type hwParams struct {
+	flags     uint32
+	masks     [3]mask
+	mres      [5]mask
+	intervals [12]interval
+	ires      [9]interval
+	rmask     uint32
+	cmask     uint32
+	info      uint32
+	msbits    uint32
+	rateNum   uint32
+	rateDen   uint32
+	fifoSize  uint
+	reserved  [64]byte
+}
+
+type xferi struct {
+	result int
+	buf    unsafe.Pointer
+	frames uint
+}
+
+var (
+	ioctlHwRefine     = alsa.IOC(3, 'A', 0x10, unsafe.Sizeof(hwParams{}))
+	ioctlHwParams     = alsa.IOC(3, 'A', 0x11, unsafe.Sizeof(hwParams{}))
+	ioctlPrepare      = alsa.IOC(0, 'A', 0x40, 0)
+	ioctlDrop         = alsa.IOC(0, 'A', 0x43, 0)
+	ioctlDrain        = alsa.IOC(0, 'A', 0x44, 0)
+	ioctlWriteiFrames = alsa.IOC(1, 'A', 0x50, unsafe.Sizeof(xferi{}))
+)
+
+func newHwParams(format int) *hwParams {
+	p := &hwParams{rmask: ^uint32(0), info: ^uint32(0)}
+	for i := range p.masks {
+		for j := range p.masks[i].bits {
+			p.masks[i].bits[j] = ^uint32(0)
+		}
+	}
+	for i := range p.intervals {
+		p.intervals[i].max = ^uint32(0)
+	}
+
+	p.set(paramAccess, accessRWInterleaved)
+	p.set(paramFormat, format)
+	p.set(paramSubformat, 0)
+	return p
+}
+
+func (p *hwParams) set(param, value int) {
+	m := &p.masks[param]
+	m.bits = [8]uint32{}
+	m.bits[value/32] = 1 << (value % 32)
+}
+
+func (p *hwParams) interval(param int) *interval {
+	return &p.intervals[param-firstInterval]
+}
+
+// configure sets up the device as close to f as the hardware allows, leaving it ready to
+// play, and returns the format it ended up with
+func configure(fd int, f Format) (Format, error) {
+	var (
+		p    *hwParams
+		bits int
+		err  error
+	)
+	for _, format := range []struct{ alsa, bits int }{{formatS16LE, 16}, {formatS32LE, 32}} {
+		p, bits = newHwParams(format.alsa), format.bits
+		if err = alsa.Ioctl(fd, ioctlHwRefine, unsafe.Pointer(p)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return Format{}, errors.Wrap(err, "no supported sample format")
+	}
+
+	channels := p.interval(paramChannels)
+	channels.min = clamp(uint32(f.Channels), channels.min, channels.max)
+	channels.max = channels.min
+
+	// the kernel picks the lowest rate left, so this is the clip's rate or the nearest
+	// one above it
+	rate := p.interval(paramRate)
+	rate.min = clamp(uint32(f.SampleRate), rate.min, rate.max)
+
+	// periods of at least 10ms so the writer isn't woken up for every few frames
+	period := p.interval(paramPeriodTime)
+	period.min = clamp(10000, period.min, period.max)
+
+	p.rmask = ^uint32(0)
+	if err := alsa.Ioctl(fd, ioctlHwParams, unsafe.Pointer(p)); err != nil {
+		return Format{}, errors.Wrap(err, "hw params")
+	}
+
+	if err := alsa.Ioctl(fd, ioctlPrepare, nil); err != nil {
+		return Format{}, errors.Wrap(err, "prepare")
+	}
+
+	return Format{
+		SampleRate:    int(p.interval(paramRate).min),
+		Channels:      int(p.interval(paramChannels).min),
+		BitsPerSample: bits,
+	}, nil
+}
+
+func clamp(v, min, max uint32) uint32 {
+	switch {
+	case v < min:
+		return min
+	case v > max:
+		return max
+	default:
+		return v
+	}
+}
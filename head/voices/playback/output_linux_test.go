@@ -0,0 +1,78 @@
+package playback
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"unsafe"
+)
+
+// The expected values come from include/uapi/sound/asound.h built with gcc for each
+// architecture the heads run on. Run with GOARCH=arm, arm64, 386 and amd64.
+func TestALSALayout(t *testing.T) {
+	type layout struct {
+		hwParams, xferi         uintptr
+		fifoSize, reserved      uintptr
+		xferiBuf, xferiFrames   uintptr
+		hwRefine, hwParamsIoctl uintptr
+		writei                  uintptr
+	}
+
+	expected := layout{
+		hwParams: 608, xferi: 24,
+		fifoSize: 536, reserved: 544,
+		xferiBuf: 8, xferiFrames: 16,
+		hwRefine: 0xc2604110, hwParamsIoctl: 0xc2604111,
+		writei: 0x40184150,
+	}
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		expected = layout{
+			hwParams: 604, xferi: 12,
+			fifoSize: 536, reserved: 540,
+			xferiBuf: 4, xferiFrames: 8,
+			hwRefine: 0xc25c4110, hwParamsIoctl: 0xc25c4111,
+			writei: 0x400c4150,
+		}
+	}
+
+	var p hwParams
+	var x xferi
+	assert.Equal(t, expected, layout{
+		hwParams: unsafe.Sizeof(p), xferi: unsafe.Sizeof(x),
+		fifoSize: unsafe.Offsetof(p.fifoSize), reserved: unsafe.Offsetof(p.reserved),
+		xferiBuf: unsafe.Offsetof(x.buf), xferiFrames: unsafe.Offsetof(x.frames),
+		hwRefine: ioctlHwRefine, hwParamsIoctl: ioctlHwParams,
+		writei: ioctlWriteiFrames,
+	})
+
+	// the same on every architecture
+	assert.Equal(t, uintptr(4), unsafe.Offsetof(p.masks))
+	assert.Equal(t, uintptr(100), unsafe.Offsetof(p.mres))
+	assert.Equal(t, uintptr(260), unsafe.Offsetof(p.intervals))
+	assert.Equal(t, uintptr(404), unsafe.Offsetof(p.ires))
+	assert.Equal(t, uintptr(512), unsafe.Offsetof(p.rmask))
+	assert.Equal(t, uintptr(528), unsafe.Offsetof(p.rateNum))
+	assert.Equal(t, uintptr(12), unsafe.Sizeof(interval{}))
+
+	assert.Equal(t, uintptr(0x4140), ioctlPrepare)
+	assert.Equal(t, uintptr(0x4143), ioctlDrop)
+	assert.Equal(t, uintptr(0x4144), ioctlDrain)
+}
+
+func TestNewHwParams(t *testing.T) {
+	p := newHwParams(formatS32LE)
+
+	assert.Equal(t, [8]uint32{1 << accessRWInterleaved}, p.masks[paramAccess].bits)
+	assert.Equal(t, [8]uint32{1 << formatS32LE}, p.masks[paramFormat].bits)
+	assert.Equal(t, [8]uint32{1}, p.masks[paramSubformat].bits)
+
+	rate := p.interval(paramRate)
+	assert.Equal(t, &p.intervals[3], rate)
+	assert.Equal(t, uint32(0), rate.min)
+	assert.Equal(t, ^uint32(0), rate.max)
+}
+
+func TestPCMDevice(t *testing.T) {
+	path, err := pcmDevice("2")
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/snd/pcmC2D0p", path)
+}
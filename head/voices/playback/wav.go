@@ -0,0 +1,119 @@
+package playback
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	formatPCM        = 1
+	formatFloat      = 3
+	formatExtensible = 0xfffe
+)
+
+type Format struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	Float         bool
+}
+
+func (f Format) bytesPerFrame() int {
+	return f.Channels * f.BitsPerSample / 8
+}
+
+type Clip struct {
+	Name   string
+	Format Format
+	PCM    []byte
+
+	// Envelope is filled in on load when something wants to follow the clip's loudness,
+	// so it isn't worked out while the clip is about to start
+	Envelope []float32
+}
+
+func (c *Clip) Duration() time.Duration {
+	return Duration(c.Format, len(c.PCM))
+}
+
+// Duration of n bytes of audio in the given format
+func Duration(f Format, n int) time.Duration {
+	bytesPerSecond := f.bytesPerFrame() * f.SampleRate
+	if bytesPerSecond == 0 {
+		return 0
+	}
+	return time.Duration(float64(n) / float64(bytesPerSecond) * float64(time.Second))
+}
+
+// ParseWAVHeader reads the format of a RIFF/WAVE file along with where its sample data
+// starts and how long it is. content only needs to include the header.
+func ParseWAVHeader(content []byte) (f Format, offset int, size int, err error) {
+	if len(content) < 12 || string(content[0:4]) != "RIFF" || string(content[8:12]) != "WAVE" {
+		return f, 0, 0, errors.New("not a wav file")
+	}
+
+	haveFormat := false
+	pos := 12
+	for pos+8 <= len(content) {
+		id := string(content[pos : pos+4])
+		chunkSize := int(binary.LittleEndian.Uint32(content[pos+4 : pos+8]))
+		body := content[pos+8:]
+
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return f, 0, 0, errors.New("short fmt chunk")
+			}
+			tag := binary.LittleEndian.Uint16(body[0:2])
+			if tag == formatExtensible && len(body) >= 26 {
+				// the real format is the first two bytes of the sub-format GUID
+				tag = binary.LittleEndian.Uint16(body[24:26])
+			}
+			if tag != formatPCM && tag != formatFloat {
+				return f, 0, 0, fmt.Errorf("unsupported wav format %d", tag)
+			}
+
+			f = Format{
+				Channels:      int(binary.LittleEndian.Uint16(body[2:4])),
+				SampleRate:    int(binary.LittleEndian.Uint32(body[4:8])),
+				BitsPerSample: int(binary.LittleEndian.Uint16(body[14:16])),
+				Float:         tag == formatFloat,
+			}
+			haveFormat = true
+
+		case "data":
+			if !haveFormat {
+				return f, 0, 0, errors.New("data chunk before fmt chunk")
+			}
+			if f.bytesPerFrame() == 0 {
+				return f, 0, 0, errors.New("invalid wav format")
+			}
+			return f, pos + 8, chunkSize, nil
+		}
+
+		pos += 8 + chunkSize + chunkSize%2 // chunks are padded to an even size
+	}
+
+	return f, 0, 0, errors.New("no data chunk")
+}
+
+func DecodeWAV(name string, content []byte) (*Clip, error) {
+	f, offset, size, err := ParseWAVHeader(content)
+	if err != nil {
+		return nil, err
+	}
+
+	end := offset + size
+	if end > len(content) {
+		end = len(content) // some recorders leave the size at zero or too large
+	}
+	end -= (end - offset) % f.bytesPerFrame()
+
+	return &Clip{
+		Name:   name,
+		Format: f,
+		PCM:    content[offset:end],
+	}, nil
+}
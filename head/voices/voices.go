@@ -2,119 +2,242 @@ package voices
 
 import (
 	"context"
-	"fmt"
-	"github.com/minor-industries/platform/common/util"
 	"github.com/minor-industries/protobuf/gen/go/heads"
+	"github.com/minor-industries/theheads/head/voices/catalog"
+	"github.com/minor-industries/theheads/head/voices/mixer"
+	"github.com/minor-industries/theheads/head/voices/playback"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"math/rand"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"net"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
-	"sync/atomic"
+	"time"
 )
 
 type Cfg struct {
-	MediaPath string `envconfig:"optional"`
+	MediaPath   string        `envconfig:"optional"`
+	MediaRescan time.Duration `envconfig:"default=30s"`
 
+	Mixer         string `envconfig:"default=device"` // device or fake
 	Card          string `envconfig:"default=Device"`
 	VolumeControl string `envconfig:"default=Speaker"`
+
+	Output    string `envconfig:"default=device"` // device or null
+	QueueSize int    `envconfig:"default=4"`
+
+	LipSync  bool   `envconfig:"default=true"` // pulse the stand's leds with the voice
+	LedsAddr string `envconfig:"default=localhost:8082"`
 }
 
 type Server struct {
 	listener net.Listener
 	cfg      *Cfg
 	logger   *zap.Logger
-
-	playing int32
+	engine   *playback.Engine
+	catalog  *catalog.Catalog
+	mixer    mixer.Mixer
 }
 
 func (s *Server) SetVolume(ctx context.Context, in *heads.SetVolumeIn) (*heads.Empty, error) {
-	if in.VolDb >= 0 {
-		return nil, errors.New("volume (decibels) must be negative")
+	s.logger.Info("setting volume", zap.Int32("db", in.VolDb))
+
+	if err := s.mixer.SetDB(float64(in.VolDb)); err != nil {
+		s.logger.Error("error setting volume", zap.Error(err))
+		return nil, errors.Wrap(err, "setting volume")
 	}
 
-	args := []string{
-		"amixer",
-		"-D", fmt.Sprintf("hw:CARD=%s", s.cfg.Card),
-		"sset", s.cfg.VolumeControl,
-		"--", fmt.Sprintf("%ddb", in.VolDb),
+	return &heads.Empty{}, nil
+}
+
+func (s *Server) GetVolume(ctx context.Context, empty *heads.Empty) (*heads.Volume, error) {
+	level, err := s.mixer.Get()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting volume")
 	}
 
-	s.logger.Info("running amixer", zap.String("command_line", strings.Join(args, " ")))
+	return &heads.Volume{
+		VolDb:   level.DB,
+		Percent: int32(level.Percent),
+		Muted:   level.Muted,
+	}, nil
+}
 
-	cmd := exec.Command(args[0], args[1:]...)
+func (s *Server) load(entry *catalog.Entry) (*playback.Clip, error) {
+	content, err := os.ReadFile(entry.Path)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "media not found")
+	}
 
-	output, err := cmd.CombinedOutput()
+	clip, err := playback.DecodeWAV(entry.Name, content)
 	if err != nil {
-		s.logger.Error("error setting volume", zap.Error(err), zap.String("output", string(output)))
-		return nil, errors.Wrap(err, "setting volume")
+		return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "decode").Error())
 	}
 
-	return &heads.Empty{}, nil
+	if s.cfg.LipSync {
+		clip.Envelope = playback.Envelope(clip, envelopeFrame)
+	}
+	return clip, nil
 }
 
-func (s *Server) Play(ctx context.Context, in *heads.PlayIn) (*heads.Empty, error) {
-	// TODO: prevent ../path style attacks
-	filename := filepath.Join(
-		s.cfg.MediaPath,
-		in.Sound,
-	)
+func (s *Server) resolve(sound string) (*catalog.Entry, error) {
+	entry, err := s.catalog.Resolve(sound)
+	switch err {
+	case nil:
+		return entry, nil
+	case catalog.ErrOutsideRoot:
+		s.logger.Warn("rejecting media path", zap.String("sound", sound))
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case catalog.ErrNotFound:
+		return nil, status.Error(codes.NotFound, err.Error())
+	default:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+}
 
-	logger := s.logger.With(zap.String("filename", filename))
+func (s *Server) random(tag string) (*catalog.Entry, error) {
+	entry, err := s.catalog.Random(tag)
+	if err != nil {
+		if tag == "" {
+			return nil, status.Error(codes.NotFound, "no media found")
+		}
+		return nil, status.Errorf(codes.NotFound, "no media tagged %q", tag)
+	}
+	return entry, nil
+}
 
-	if !util.Exists(filename) {
-		logger.Error("media not found")
-		return nil, status.Error(codes.NotFound, "media not found")
+func (s *Server) Play(ctx context.Context, in *heads.PlayIn) (*heads.Empty, error) {
+	entry, err := s.resolve(in.Sound)
+	if err != nil {
+		return nil, err
 	}
 
-	err := s.play(filename)
-	if err == AlreadyPlayingErr {
-		logger.Warn("already playing", zap.Error(err))
-		return nil, status.Error(codes.Unavailable, "already playing")
-	} else if err != nil {
-		logger.Error("error playing", zap.Error(err))
-		return nil, status.Error(codes.Internal, "already playing")
+	if err := s.play(ctx, entry, int(in.Priority)); err != nil {
+		return nil, err
 	}
 
 	return &heads.Empty{}, nil
 }
 
 func (s *Server) Random(ctx context.Context, empty *heads.Empty) (*heads.Empty, error) {
-	var files []string
-	err := filepath.Walk(s.cfg.MediaPath, func(path string, info os.FileInfo, err error) error {
-		if strings.HasSuffix(path, ".wav") {
-			files = append(files, path)
-		}
-		return nil
-	})
+	entry, err := s.random("")
 	if err != nil {
-		return nil, errors.Wrap(err, "walk")
+		return nil, err
 	}
 
-	if len(files) == 0 {
-		return nil, errors.New("no media found")
+	if err := s.play(ctx, entry, 0); err != nil {
+		return nil, err
 	}
 
-	n := rand.Intn(len(files))
-	choice := files[n]
+	return &heads.Empty{}, nil
+}
 
-	err = s.play(choice)
+func (s *Server) RandomByTag(ctx context.Context, in *heads.RandomByTagIn) (*heads.Media, error) {
+	entry, err := s.random(in.Tag)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.play(ctx, entry, int(in.Priority)); err != nil {
+		return nil, err
+	}
+
+	return media(entry), nil
+}
+
+func (s *Server) ListMedia(ctx context.Context, in *heads.ListMediaIn) (*heads.MediaList, error) {
+	result := &heads.MediaList{}
+	for _, entry := range s.catalog.List(in.Tag) {
+		result.Media = append(result.Media, media(entry))
+	}
+	return result, nil
+}
+
+func media(entry *catalog.Entry) *heads.Media {
+	return &heads.Media{
+		Name:     entry.Name,
+		Duration: durationpb.New(entry.Duration),
+		Tags:     entry.Tags,
+	}
+}
+
+const maxScheduleAhead = time.Minute
+
+func (s *Server) PlayAt(ctx context.Context, in *heads.PlayAtIn) (*heads.Empty, error) {
+	at := in.At.AsTime()
+	if time.Until(at) > maxScheduleAhead {
+		return nil, status.Error(codes.InvalidArgument, "too far in the future")
+	}
+
+	var entry *catalog.Entry
+	var err error
+	if in.Random {
+		entry, err = s.random(in.Tag)
+	} else {
+		entry, err = s.resolve(in.Sound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	clip, err := s.load(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.enqueue(playback.Request{Clip: clip, Priority: int(in.Priority), At: at}); err != nil {
+		return nil, err
+	}
+
 	return &heads.Empty{}, nil
 }
 
+func (s *Server) Stop(ctx context.Context, in *heads.StopIn) (*heads.Empty, error) {
+	s.engine.Stop(in.ClearQueue)
+	return &heads.Empty{}, nil
+}
+
+func (s *Server) Status(ctx context.Context, empty *heads.Empty) (*heads.VoicesStatus, error) {
+	st := s.engine.Status()
+	return &heads.VoicesStatus{
+		Playing:  st.Playing,
+		Sound:    st.Clip,
+		Priority: int32(st.Priority),
+		Position: durationpb.New(st.Position),
+		Length:   durationpb.New(st.Length),
+		Queued:   st.Queued,
+	}, nil
+}
+
 func NewServer(cfg *Cfg, logger *zap.Logger) *Server {
+	output, err := playback.NewOutput(cfg.Output, cfg.Card)
+	if err != nil {
+		panic(err)
+	}
+
+	m, err := mixer.New(cfg.Mixer, cfg.Card, cfg.VolumeControl)
+	if err != nil {
+		panic(err)
+	}
+
+	var observer playback.Observer
+	if cfg.LipSync {
+		observer = newLipSync(logger, cfg.LedsAddr)
+	}
+
+	engine := playback.NewEngine(logger, output, cfg.QueueSize, observer)
+	go engine.Run()
+
+	media := catalog.New(logger, cfg.MediaPath)
+	go media.Watch(cfg.MediaRescan)
+
 	return &Server{
-		cfg:    cfg,
-		logger: logger,
+		cfg:     cfg,
+		logger:  logger,
+		engine:  engine,
+		catalog: media,
+		mixer:   m,
 	}
 }
 
@@ -128,35 +251,45 @@ func (s *Server) Setup() error {
 	return nil
 }
 
-var AlreadyPlayingErr = errors.New("already playing")
-
-func (s *Server) play(filename string) error {
-	logger := s.logger.With(zap.String("filename", filename))
-
-	swapped := atomic.CompareAndSwapInt32(&s.playing, 0, 1)
-	defer atomic.StoreInt32(&s.playing, 0)
-
-	args := append(execPlay(s.cfg.Card), filename)
+func (s *Server) enqueue(req playback.Request) (*playback.Job, error) {
+	job, err := s.engine.Enqueue(req)
+	if err == playback.ErrQueueFull {
+		return nil, status.Error(codes.ResourceExhausted, "queue full")
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return job, nil
+}
 
-	logger.Debug("play",
-		zap.Bool("swapped", swapped),
-		zap.String("command_line", strings.Join(args, " ")),
-	)
+// play queues the clip and waits for it to finish
+func (s *Server) play(ctx context.Context, entry *catalog.Entry, priority int) error {
+	logger := s.logger.With(zap.String("sound", entry.Name))
 
-	if !swapped {
-		return AlreadyPlayingErr
+	clip, err := s.load(entry)
+	if err != nil {
+		logger.Error("error loading", zap.Error(err))
+		return err
 	}
 
-	cmd := exec.Command(args[0], args[1:]...)
-	output, err := cmd.CombinedOutput()
+	job, err := s.enqueue(playback.Request{Clip: clip, Priority: priority})
 	if err != nil {
-		logger.Error("error playing",
-			zap.Error(err),
-			zap.String("output", string(output)),
-			zap.String("filename", filename),
-		)
-		return errors.Wrap(err, "playing")
+		logger.Warn("error queueing", zap.Error(err))
+		return err
 	}
 
-	return nil
+	select {
+	case <-job.Done():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	switch err := job.Err(); err {
+	case nil:
+		return nil
+	case playback.ErrStopped, playback.ErrPreempted:
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		logger.Error("error playing", zap.Error(err))
+		return status.Error(codes.Internal, "error playing")
+	}
 }
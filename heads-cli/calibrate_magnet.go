@@ -0,0 +1,45 @@
+package heads_cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/serf/client"
+	heads2 "github.com/minor-industries/protobuf/gen/go/heads"
+	"github.com/minor-industries/theheads/heads-cli/lib"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type CalibrateMagnetCmd struct {
+	Match  string `long:"match" description:"host pattern to match" default:"^head"`
+	OutDir string `long:"out-dir" description:"directory to write each head's plot to" default:"."`
+}
+
+func (opt *CalibrateMagnetCmd) Execute(args []string) error {
+	return lib.ConnectAll(opt.Match, 8080, func(ctx context.Context, m *client.Member, conn *grpc.ClientConn) error {
+		fmt.Println("calibrate magnet sensor for", m.Name)
+
+		// a full turn takes longer than connecting is allowed to
+		calCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		cal, err := heads2.NewHeadClient(conn).CalibrateMagnet(calCtx, &heads2.Empty{})
+		if err != nil {
+			return errors.Wrap(err, "calibrate magnet")
+		}
+
+		fmt.Printf(
+			"%s: %d samples, reference %.1fF, drift x=%.4f y=%.4f z=%.4f mT/F\n",
+			m.Name, cal.Samples, cal.Reference, cal.Coefficient[0], cal.Coefficient[1], cal.Coefficient[2],
+		)
+		if !cal.FieldMap {
+			fmt.Println(m.Name, "wasn't zeroed, so its field map was left as it was")
+		}
+
+		filename := filepath.Join(opt.OutDir, m.Name+"-calibration.svg")
+		return errors.Wrap(os.WriteFile(filename, cal.Plot, 0o644), "write plot")
+	})
+}
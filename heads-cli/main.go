@@ -32,6 +32,7 @@ func Run() error {
 	}{
 		{Name: "all", Data: &allCommand{}},
 		{Name: "assign-ip", Data: &assignIPsCommand},
+		{Name: "calibrate-magnet", Data: &CalibrateMagnetCmd{}},
 		{Name: "diag", Data: &DiagCmd{}},
 		{Name: "discover", Data: &DiscoverCmd{}},
 		{Name: "env2dict", Data: &Env2DictCmd{}},
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"math"
 	"time"
 )
@@ -35,22 +36,35 @@ func (opt *PositionCmd) Execute(args []string) error {
 		}
 	}
 
+	watch, err := client.WatchState(context.Background(), &heads2.WatchStateIn{
+		Interval: durationpb.New(250 * time.Millisecond),
+	})
+	noError(err)
+
 	go func() {
-		for range time.NewTicker(1000 * time.Millisecond).C {
-			status, err := client.Status(context.Background(), &heads2.Empty{})
+		for {
+			status, err := watch.Recv()
 			noError(err)
 
 			sensor, err := client.ReadMagnetSensor(context.Background(), &heads2.Empty{})
 			noError(err)
 
+			// -1 until the head has a field map to estimate from
+			estimate, confidence := int32(-1), 0.0
+			if status.Estimate != nil {
+				estimate, confidence = status.Estimate.Position, status.Estimate.Confidence
+			}
+
 			val := math.Abs(sensor.Bz) - math.Abs(sensor.By)
 			fmt.Printf(
-				"%d, %02.02f, %02.02f, %02.02f, %02.02f\n",
+				"%d, %02.02f, %02.02f, %02.02f, %02.02f, %d, %.02f\n",
 				status.Position,
 				val,
 				sensor.Bx,
 				sensor.By,
 				sensor.Bz,
+				estimate,
+				confidence,
 			)
 		}
 	}()
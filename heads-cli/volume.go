@@ -12,15 +12,26 @@ import (
 
 type volumeCmd struct {
 	Match string `long:"match" description:"host pattern to match" default:"^head"`
-	Vol   int    `long:"vol" description:"volume db to use" required:"true"`
+	Vol   *int   `long:"vol" description:"volume db to use, leave out to show the current volume"`
 }
 
 func (opt *volumeCmd) Execute(args []string) error {
 	return lib.ConnectAll(opt.Match, 8080, func(ctx context.Context, m *client.Member, conn *grpc.ClientConn) error {
+		client := heads.NewVoicesClient(conn)
+
+		if opt.Vol == nil {
+			vol, err := client.GetVolume(ctx, &heads.Empty{})
+			if err != nil {
+				return errors.Wrap(err, "get volume")
+			}
+			fmt.Printf("%s: %.2fdB (%d%%) muted=%v\n", m.Name, vol.VolDb, vol.Percent, vol.Muted)
+			return nil
+		}
+
 		fmt.Println("setting volume for", m.Name)
 
-		_, err := heads.NewVoicesClient(conn).SetVolume(ctx, &heads.SetVolumeIn{
-			VolDb: int32(opt.Vol),
+		_, err := client.SetVolume(ctx, &heads.SetVolumeIn{
+			VolDb: int32(*opt.Vol),
 		})
 
 		return errors.Wrap(err, "set volume")
@@ -8,17 +8,46 @@ import (
 	"github.com/minor-industries/theheads/heads-cli/lib"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"time"
 )
 
 type FindZeroCmd struct {
 	Match string `long:"match" description:"host pattern to match" default:"^head"`
+	Wait  bool   `long:"wait" description:"wait for each head to find its zero"`
 }
 
 func (opt *FindZeroCmd) Execute(args []string) error {
 	return lib.ConnectAll(opt.Match, 8080, func(ctx context.Context, m *client.Member, conn *grpc.ClientConn) error {
 		fmt.Println("find zero for", m.Name)
 
-		_, err := heads2.NewHeadClient(conn).FindZero(ctx, &heads2.Empty{})
-		return errors.Wrap(err, "find zero")
+		client := heads2.NewHeadClient(conn)
+		if _, err := client.FindZero(ctx, &heads2.Empty{}); err != nil {
+			return errors.Wrap(err, "find zero")
+		}
+		if !opt.Wait {
+			return nil
+		}
+
+		// a search takes longer than connecting is allowed to
+		waitCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		watch, err := client.WatchState(waitCtx, &heads2.WatchStateIn{})
+		if err != nil {
+			return errors.Wrap(err, "watch state")
+		}
+		for {
+			state, err := watch.Recv()
+			if err != nil {
+				return errors.Wrap(err, "recv")
+			}
+			switch state.ZeroStatus {
+			case heads2.ZeroStatus_ZERO_FOUND:
+				fmt.Println("found zero for", m.Name)
+				return nil
+			case heads2.ZeroStatus_ZERO_FAILED:
+				return fmt.Errorf("%s: %s", m.Name, state.ZeroError)
+			}
+		}
 	})
 }
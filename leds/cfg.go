@@ -1,6 +1,9 @@
 package leds
 
-import "time"
+import (
+	tomlconfig "github.com/minor-industries/theheads/config"
+	"time"
+)
 
 type config struct {
 	NumLeds    int     `envconfig:"default=150"`
@@ -17,6 +20,8 @@ type config struct {
 
 	Lowred float64 `envconfig:"default=0.5"`
 
+	LipSyncFloor float64 `envconfig:"default=0.15"` // brightness while the voice is silent
+
 	Range struct {
 		R float64 `envconfig:"default=0.75"`
 		G float64 `envconfig:"default=0.75"`
@@ -27,3 +32,14 @@ type config struct {
 
 	ConfigFile string `envconfig:"default=/boot/leds-cfg.json"`
 }
+
+func (c *config) Validate() error {
+	check := &tomlconfig.Check{}
+	check.That(c.NumLeds > 0, "NumLeds must be positive")
+	check.That(c.StartIndex >= 0 && c.StartIndex < c.NumLeds, "StartIndex must be within NumLeds")
+	check.That(c.Length > 0, "Length must be positive")
+	check.That(c.UpdatePeriod > 0, "UpdatePeriod must be positive")
+	check.That(c.MinScale >= 0 && c.MinScale <= 1, "MinScale must be between 0 and 1")
+	check.That(c.LipSyncFloor >= 0 && c.LipSyncFloor <= 1, "LipSyncFloor must be between 0 and 1")
+	return check.Err()
+}
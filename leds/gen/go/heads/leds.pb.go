@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.23.0
+// 	protoc-gen-go v1.31.0
 // 	protoc        v3.18.1
 // source: leds.proto
 
@@ -8,12 +8,12 @@ package heads
 
 import (
 	context "context"
-	proto "github.com/golang/protobuf/proto"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -26,10 +26,6 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// This is a compile-time assertion that a sufficiently up-to-date version
-// of the legacy proto package is being used.
-const _ = proto.ProtoPackageIsVersion4
-
 type RunIn struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -132,11 +128,76 @@ func (x *SetScaleIn) GetScale() float64 {
 	return 0
 }
 
+type EnvelopeIn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	Frame  *durationpb.Duration   `protobuf:"bytes,2,opt,name=frame,proto3" json:"frame,omitempty"`
+	Levels []float32              `protobuf:"fixed32,3,rep,packed,name=levels,proto3" json:"levels,omitempty"`
+}
+
+func (x *EnvelopeIn) Reset() {
+	*x = EnvelopeIn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_leds_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EnvelopeIn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnvelopeIn) ProtoMessage() {}
+
+func (x *EnvelopeIn) ProtoReflect() protoreflect.Message {
+	mi := &file_leds_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnvelopeIn.ProtoReflect.Descriptor instead.
+func (*EnvelopeIn) Descriptor() ([]byte, []int) {
+	return file_leds_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EnvelopeIn) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *EnvelopeIn) GetFrame() *durationpb.Duration {
+	if x != nil {
+		return x.Frame
+	}
+	return nil
+}
+
+func (x *EnvelopeIn) GetLevels() []float32 {
+	if x != nil {
+		return x.Levels
+	}
+	return nil
+}
+
 var File_leds_proto protoreflect.FileDescriptor
 
 var file_leds_proto_rawDesc = []byte{
 	0x0a, 0x0a, 0x6c, 0x65, 0x64, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x68, 0x65,
 	0x61, 0x64, 0x73, 0x1a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
 	0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x22, 0x5d, 0x0a, 0x05, 0x52, 0x75, 0x6e, 0x49, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e,
@@ -147,15 +208,27 @@ var file_leds_proto_rawDesc = []byte{
 	0x61, 0x6d, 0x70, 0x52, 0x0c, 0x6e, 0x65, 0x77, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d,
 	0x65, 0x22, 0x22, 0x0a, 0x0a, 0x53, 0x65, 0x74, 0x53, 0x63, 0x61, 0x6c, 0x65, 0x49, 0x6e, 0x12,
 	0x14, 0x0a, 0x05, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05,
-	0x73, 0x63, 0x61, 0x6c, 0x65, 0x32, 0x7f, 0x0a, 0x04, 0x6c, 0x65, 0x64, 0x73, 0x12, 0x21, 0x0a,
-	0x03, 0x72, 0x75, 0x6e, 0x12, 0x0c, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x52, 0x75, 0x6e,
-	0x49, 0x6e, 0x1a, 0x0c, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
-	0x12, 0x26, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x0c, 0x2e, 0x68, 0x65, 0x61,
-	0x64, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0c, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73,
-	0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x12, 0x2c, 0x0a, 0x09, 0x73, 0x65, 0x74, 0x5f,
-	0x73, 0x63, 0x61, 0x6c, 0x65, 0x12, 0x11, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x53, 0x65,
-	0x74, 0x53, 0x63, 0x61, 0x6c, 0x65, 0x49, 0x6e, 0x1a, 0x0c, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73,
-	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x73, 0x63, 0x61, 0x6c, 0x65, 0x22, 0x87, 0x01, 0x0a, 0x0a, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f,
+	0x70, 0x65, 0x49, 0x6e, 0x12, 0x30, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x2f, 0x0a, 0x05, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x05, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x76, 0x65, 0x6c,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x02, 0x52, 0x06, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x32,
+	0xac, 0x01, 0x0a, 0x04, 0x6c, 0x65, 0x64, 0x73, 0x12, 0x21, 0x0a, 0x03, 0x72, 0x75, 0x6e, 0x12,
+	0x0c, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x52, 0x75, 0x6e, 0x49, 0x6e, 0x1a, 0x0c, 0x2e,
+	0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x26, 0x0a, 0x06, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x0c, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x1a, 0x0c, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x30, 0x01, 0x12, 0x2c, 0x0a, 0x09, 0x73, 0x65, 0x74, 0x5f, 0x73, 0x63, 0x61, 0x6c, 0x65,
+	0x12, 0x11, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x63, 0x61, 0x6c,
+	0x65, 0x49, 0x6e, 0x1a, 0x0c, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x12, 0x2b, 0x0a, 0x08, 0x65, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x12, 0x11, 0x2e,
+	0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x49, 0x6e,
+	0x1a, 0x0c, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x73, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -170,27 +243,33 @@ func file_leds_proto_rawDescGZIP() []byte {
 	return file_leds_proto_rawDescData
 }
 
-var file_leds_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_leds_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_leds_proto_goTypes = []interface{}{
 	(*RunIn)(nil),                 // 0: heads.RunIn
 	(*SetScaleIn)(nil),            // 1: heads.SetScaleIn
-	(*timestamppb.Timestamp)(nil), // 2: google.protobuf.Timestamp
-	(*Empty)(nil),                 // 3: heads.Empty
-	(*Event)(nil),                 // 4: heads.Event
+	(*EnvelopeIn)(nil),            // 2: heads.EnvelopeIn
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 4: google.protobuf.Duration
+	(*Empty)(nil),                 // 5: heads.Empty
+	(*Event)(nil),                 // 6: heads.Event
 }
 var file_leds_proto_depIdxs = []int32{
-	2, // 0: heads.RunIn.new_start_time:type_name -> google.protobuf.Timestamp
-	0, // 1: heads.leds.run:input_type -> heads.RunIn
-	3, // 2: heads.leds.events:input_type -> heads.Empty
-	1, // 3: heads.leds.set_scale:input_type -> heads.SetScaleIn
-	3, // 4: heads.leds.run:output_type -> heads.Empty
-	4, // 5: heads.leds.events:output_type -> heads.Event
-	3, // 6: heads.leds.set_scale:output_type -> heads.Empty
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3, // 0: heads.RunIn.new_start_time:type_name -> google.protobuf.Timestamp
+	3, // 1: heads.EnvelopeIn.start:type_name -> google.protobuf.Timestamp
+	4, // 2: heads.EnvelopeIn.frame:type_name -> google.protobuf.Duration
+	0, // 3: heads.leds.run:input_type -> heads.RunIn
+	5, // 4: heads.leds.events:input_type -> heads.Empty
+	1, // 5: heads.leds.set_scale:input_type -> heads.SetScaleIn
+	2, // 6: heads.leds.envelope:input_type -> heads.EnvelopeIn
+	5, // 7: heads.leds.run:output_type -> heads.Empty
+	6, // 8: heads.leds.events:output_type -> heads.Event
+	5, // 9: heads.leds.set_scale:output_type -> heads.Empty
+	5, // 10: heads.leds.envelope:output_type -> heads.Empty
+	7, // [7:11] is the sub-list for method output_type
+	3, // [3:7] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_leds_proto_init() }
@@ -224,6 +303,18 @@ func file_leds_proto_init() {
 				return nil
 			}
 		}
+		file_leds_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EnvelopeIn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -231,7 +322,7 @@ func file_leds_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_leds_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -260,6 +351,7 @@ type LedsClient interface {
 	Run(ctx context.Context, in *RunIn, opts ...grpc.CallOption) (*Empty, error)
 	Events(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Leds_EventsClient, error)
 	SetScale(ctx context.Context, in *SetScaleIn, opts ...grpc.CallOption) (*Empty, error)
+	Envelope(ctx context.Context, in *EnvelopeIn, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type ledsClient struct {
@@ -320,11 +412,21 @@ func (c *ledsClient) SetScale(ctx context.Context, in *SetScaleIn, opts ...grpc.
 	return out, nil
 }
 
+func (c *ledsClient) Envelope(ctx context.Context, in *EnvelopeIn, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/heads.leds/envelope", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LedsServer is the server API for Leds service.
 type LedsServer interface {
 	Run(context.Context, *RunIn) (*Empty, error)
 	Events(*Empty, Leds_EventsServer) error
 	SetScale(context.Context, *SetScaleIn) (*Empty, error)
+	Envelope(context.Context, *EnvelopeIn) (*Empty, error)
 }
 
 // UnimplementedLedsServer can be embedded to have forward compatible implementations.
@@ -340,6 +442,9 @@ func (*UnimplementedLedsServer) Events(*Empty, Leds_EventsServer) error {
 func (*UnimplementedLedsServer) SetScale(context.Context, *SetScaleIn) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetScale not implemented")
 }
+func (*UnimplementedLedsServer) Envelope(context.Context, *EnvelopeIn) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Envelope not implemented")
+}
 
 func RegisterLedsServer(s *grpc.Server, srv LedsServer) {
 	s.RegisterService(&_Leds_serviceDesc, srv)
@@ -402,6 +507,24 @@ func _Leds_SetScale_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Leds_Envelope_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnvelopeIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedsServer).Envelope(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/heads.leds/Envelope",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedsServer).Envelope(ctx, req.(*EnvelopeIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Leds_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "heads.leds",
 	HandlerType: (*LedsServer)(nil),
@@ -414,6 +537,10 @@ var _Leds_serviceDesc = grpc.ServiceDesc{
 			MethodName: "set_scale",
 			Handler:    _Leds_SetScale_Handler,
 		},
+		{
+			MethodName: "envelope",
+			Handler:    _Leds_Envelope_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
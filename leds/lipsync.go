@@ -0,0 +1,75 @@
+package leds
+
+import (
+	"sync"
+	"time"
+)
+
+// lipSync holds the amplitude envelope of the clip the head on this stand is playing
+type lipSync struct {
+	lock   sync.Mutex
+	start  time.Time
+	frame  time.Duration
+	levels []float32
+}
+
+func (l *lipSync) set(start time.Time, frame time.Duration, levels []float32) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.start = start
+	l.frame = frame
+	l.levels = levels
+}
+
+// level returns the envelope at t, interpolated between frames. ok is false when
+// nothing is playing.
+func (l *lipSync) level(t time.Time) (float64, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if len(l.levels) == 0 || l.frame <= 0 {
+		return 0, false
+	}
+
+	pos := float64(t.Sub(l.start)) / float64(l.frame)
+	if pos < 0 {
+		return 0, false
+	}
+	if pos >= float64(len(l.levels)) {
+		l.levels = nil
+		return 0, false
+	}
+
+	i := int(pos)
+	x0 := float64(l.levels[i])
+	x1 := x0
+	if i+1 < len(l.levels) {
+		x1 = float64(l.levels[i+1])
+	}
+	frac := pos - float64(i)
+	return clamp(0, x0+(x1-x0)*frac, 1), true
+}
+
+// send renders the current frame, dimmed in time with the voice while one is playing.
+// The animation's own values are left alone since some of them build on the last frame.
+func (app *App) send(now time.Time) error {
+	level, ok := app.lipSync.level(now)
+	if !ok {
+		return app.strip.send2()
+	}
+
+	floor := app.env.LipSyncFloor
+	gain := floor + (1-floor)*level
+
+	saved := append([]Led(nil), app.strip.leds...)
+	for i := range app.strip.leds {
+		app.strip.leds[i].r *= gain
+		app.strip.leds[i].g *= gain
+		app.strip.leds[i].b *= gain
+	}
+
+	err := app.strip.send2()
+	copy(app.strip.leds, saved)
+	return err
+}
@@ -0,0 +1,30 @@
+package leds
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLipSyncLevel(t *testing.T) {
+	l := &lipSync{}
+	start := time.Now()
+
+	_, ok := l.level(start)
+	assert.False(t, ok)
+
+	l.set(start, 100*time.Millisecond, []float32{0, 1, 0.5})
+
+	_, ok = l.level(start.Add(-time.Millisecond))
+	assert.False(t, ok)
+
+	level, ok := l.level(start.Add(50 * time.Millisecond))
+	assert.True(t, ok)
+	assert.InDelta(t, 0.5, level, 1e-6)
+
+	level, _ = l.level(start.Add(250 * time.Millisecond))
+	assert.InDelta(t, 0.5, level, 1e-6)
+
+	_, ok = l.level(start.Add(300 * time.Millisecond))
+	assert.False(t, ok)
+}
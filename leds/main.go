@@ -7,10 +7,11 @@ import (
 	"github.com/minor-industries/platform/common/broker"
 	"github.com/minor-industries/platform/common/standard_server"
 	"github.com/minor-industries/platform/common/util"
+	tomlconfig "github.com/minor-industries/theheads/config"
 	"github.com/minor-industries/theheads/leds/gen/go/heads"
+	"github.com/minor-industries/theheads/schedule"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/vrischmann/envconfig"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -96,9 +97,9 @@ func mainloop(app *App, currentAnimation string) error {
 			cb := app.animations[currentAnimation]
 			cb(t, dt)
 			t0 = now
-			err := app.strip.send2()
+			err := app.send(now)
 			if err != nil {
-				return errors.Wrap(err, "send2")
+				return errors.Wrap(err, "send")
 			}
 		case <-app.done:
 			return nil
@@ -128,6 +129,7 @@ type App struct {
 	animations       map[string]callback
 	done             chan bool
 	currentAnimation *atomic.String
+	lipSync          lipSync
 }
 
 type settings struct {
@@ -185,9 +187,9 @@ func Run(logger *zap.Logger) error {
 
 	app.env = &config{}
 
-	err := envconfig.Init(app.env)
+	err := tomlconfig.Load(tomlconfig.Path("leds"), app.env)
 	if err != nil {
-		panic(err)
+		return errors.Wrap(err, "load config")
 	}
 
 	settings := &settings{
@@ -251,12 +253,16 @@ func Run(logger *zap.Logger) error {
 	var h = &Handler{
 		app: app,
 	}
+	const port = 8082
 	server, err := standard_server.NewServer(&standard_server.Config{
 		Logger: logger,
-		Port:   8082,
+		Port:   port,
 		GrpcSetup: func(grpcServer *grpc.Server) error {
 			heads.RegisterLedsServer(grpcServer, h)
 			heads.RegisterPingServer(grpcServer, h)
+			heads.RegisterSchedulerServer(grpcServer, newScheduler(
+				schedule.New(logger, prometheus.DefaultRegisterer, schedule.Loopback(port)),
+			))
 			return nil
 		},
 		HttpSetup: func(r *gin.Engine) error {
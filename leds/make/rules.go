@@ -9,29 +9,35 @@ import (
 func main() {
 	grm.Main(map[string]func(rule string){
 		"protos": func(rule string) {
-			protoFiles, err := filepath.Glob("protos/*.proto")
-			if err != nil {
-				panic(err)
-			}
+			// the leds service and the scheduler are part of the shared protos, so run from
+			// the top of the repo where protoc can see them as well as ours
+			grm.Cd("..", func() {
+				protoFiles, err := filepath.Glob("leds/protos/*.proto")
+				if err != nil {
+					panic(err)
+				}
+				protoFiles = append(protoFiles, "protos/leds.proto", "protos/schedule.proto")
 
-			args := []string{
-				"/bin/protoc",
-				"--proto_path=./protos",
-				"-I/build/include",
-				"--go_out=plugins=grpc,paths=source_relative:./gen/go/heads",
-			}
+				args := []string{
+					"/bin/protoc",
+					"--proto_path=./leds/protos",
+					"--proto_path=./protos",
+					"-I/build/include",
+					"--go_out=plugins=grpc,paths=source_relative:./leds/gen/go/heads",
+				}
 
-			for _, file := range protoFiles {
-				// this may run into trouble if there are two proto files with the same name in
-				// different directories
-				base := filepath.Base(file)
-				opt := fmt.Sprintf("--go_opt=M%s=github.com/minor-industries/theheads/leds/gen/go/heads", base)
-				args = append(args, opt)
-			}
+				for _, file := range protoFiles {
+					// this may run into trouble if there are two proto files with the same name in
+					// different directories
+					base := filepath.Base(file)
+					opt := fmt.Sprintf("--go_opt=M%s=github.com/minor-industries/theheads/leds/gen/go/heads", base)
+					args = append(args, opt)
+				}
 
-			args = append(args, protoFiles...)
+				args = append(args, protoFiles...)
 
-			grm.RunDocker("heads-protoc", args...)
+				grm.RunDocker("heads-protoc", args...)
+			})
 		},
 	})
 }
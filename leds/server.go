@@ -20,6 +20,14 @@ func (h *Handler) SetScale(ctx context.Context, in *heads.SetScaleIn) (*heads.Em
 	return &heads.Empty{}, nil
 }
 
+func (h *Handler) Envelope(ctx context.Context, in *heads.EnvelopeIn) (*heads.Empty, error) {
+	if len(in.Levels) > 0 && in.Frame.AsDuration() <= 0 {
+		return nil, errors.New("frame must be positive")
+	}
+	h.app.lipSync.set(in.Start.AsTime(), in.Frame.AsDuration(), in.Levels)
+	return &heads.Empty{}, nil
+}
+
 func (h *Handler) Events(empty *heads.Empty, server heads.Leds_EventsServer) error {
 	messages := h.app.broker.Subscribe()
 	defer h.app.broker.Unsubscribe(messages)
@@ -0,0 +1,48 @@
+package schedule
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type schedulerMetrics struct {
+	lateness prometheus.Histogram
+	late     prometheus.Counter
+	failed   prometheus.Counter
+	pending  prometheus.Gauge
+}
+
+// newMetrics registers the scheduler's metrics, sharing them between schedulers in the
+// same process, such as the heads run together by dev
+func newMetrics(registry prometheus.Registerer) *schedulerMetrics {
+	return &schedulerMetrics{
+		lateness: register(registry, prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "heads",
+			Subsystem: "schedule",
+			Name:      "lateness_seconds",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 13),
+		})),
+		late: register(registry, prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "heads",
+			Subsystem: "schedule",
+			Name:      "late",
+		})),
+		failed: register(registry, prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "heads",
+			Subsystem: "schedule",
+			Name:      "failed",
+		})),
+		pending: register(registry, prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "heads",
+			Subsystem: "schedule",
+			Name:      "pending",
+		})),
+	}
+}
+
+func register[T prometheus.Collector](registry prometheus.Registerer, c T) T {
+	if err := registry.Register(c); err != nil {
+		if already, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return already.ExistingCollector.(T)
+		}
+		panic(err)
+	}
+	return c
+}
@@ -0,0 +1,180 @@
+package schedule
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// commands this far out are more likely a clock problem than a plan
+	MaxAhead = 24 * time.Hour
+
+	// later than this counts as late; timesync keeps clocks closer than this
+	LateAfter = 20 * time.Millisecond
+
+	// long enough for calls that return once they're done, such as playing a clip
+	runTimeout = 5 * time.Minute
+
+	schedulerPrefix = "/heads.scheduler/"
+)
+
+var (
+	ErrNoMethod  = errors.New("method is required")
+	ErrTooFar    = errors.New("too far ahead")
+	ErrDuplicate = errors.New("id is already scheduled")
+	ErrRecursive = errors.New("the scheduler can't schedule itself")
+)
+
+// Invoke makes the call a command was scheduled for
+type Invoke func(ctx context.Context, method string, request []byte) error
+
+type Entry struct {
+	ID     string
+	At     time.Time
+	Method string
+
+	request []byte
+	timer   *time.Timer
+}
+
+// Scheduler keeps commands until their time comes, then makes them through invoke. A
+// command whose time has already passed runs straight away, and counts as late.
+type Scheduler struct {
+	logger  *zap.Logger
+	invoke  Invoke
+	metrics *schedulerMetrics
+
+	lock    sync.Mutex
+	pending map[string]*Entry
+}
+
+func New(logger *zap.Logger, registry prometheus.Registerer, invoke Invoke) *Scheduler {
+	return &Scheduler{
+		logger:  logger,
+		invoke:  invoke,
+		metrics: newMetrics(registry),
+		pending: map[string]*Entry{},
+	}
+}
+
+func (s *Scheduler) Add(id string, at time.Time, method string, request []byte) (Entry, error) {
+	switch {
+	case method == "":
+		return Entry{}, ErrNoMethod
+	case strings.HasPrefix(method, schedulerPrefix):
+		return Entry{}, ErrRecursive
+	case time.Until(at) > MaxAhead:
+		return Entry{}, ErrTooFar
+	}
+
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.pending[id]; ok {
+		return Entry{}, ErrDuplicate
+	}
+
+	e := &Entry{
+		ID:      id,
+		At:      at,
+		Method:  method,
+		request: request,
+	}
+	e.timer = time.AfterFunc(time.Until(at), func() { s.run(e) })
+	s.pending[id] = e
+	s.metrics.pending.Set(float64(len(s.pending)))
+
+	return *e, nil
+}
+
+func (s *Scheduler) run(e *Entry) {
+	s.lock.Lock()
+	if s.pending[e.ID] != e {
+		s.lock.Unlock()
+		return // cancelled as the timer fired
+	}
+	delete(s.pending, e.ID)
+	s.metrics.pending.Set(float64(len(s.pending)))
+	s.lock.Unlock()
+
+	logger := s.logger.With(zap.String("id", e.ID), zap.String("method", e.Method))
+
+	late := time.Since(e.At)
+	s.metrics.lateness.Observe(late.Seconds())
+	if late > LateAfter {
+		s.metrics.late.Inc()
+		logger.Warn("scheduled command is late", zap.Duration("late", late))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+
+	if err := s.invoke(ctx, e.Method, e.request); err != nil {
+		s.metrics.failed.Inc()
+		logger.Error("scheduled command failed", zap.Error(err))
+	}
+}
+
+// ToStatus gives the grpc status for an error from Add
+func ToStatus(id string, err error) error {
+	if err == ErrDuplicate {
+		return status.Errorf(codes.AlreadyExists, "%s: %s", id, err)
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// Cancel returns whether the command was still to run
+func (s *Scheduler) Cancel(id string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, ok := s.pending[id]
+	if !ok {
+		return false
+	}
+	e.timer.Stop()
+	delete(s.pending, id)
+	s.metrics.pending.Set(float64(len(s.pending)))
+	return true
+}
+
+func (s *Scheduler) CancelAll() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	n := len(s.pending)
+	for id, e := range s.pending {
+		e.timer.Stop()
+		delete(s.pending, id)
+	}
+	s.metrics.pending.Set(0)
+	return n
+}
+
+// Pending is what's still to run, soonest first
+func (s *Scheduler) Pending() []Entry {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := make([]Entry, 0, len(s.pending))
+	for _, e := range s.pending {
+		result = append(result, *e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].At.Before(result[j].At)
+	})
+	return result
+}
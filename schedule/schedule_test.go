@@ -0,0 +1,127 @@
+package schedule
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+type call struct {
+	method  string
+	request []byte
+	at      time.Time
+}
+
+func newTestScheduler() (*Scheduler, chan call) {
+	calls := make(chan call, 10)
+	s := New(zap.NewNop(), prometheus.NewRegistry(), func(ctx context.Context, method string, request []byte) error {
+		calls <- call{method: method, request: request, at: time.Now()}
+		return nil
+	})
+	return s, calls
+}
+
+func TestRunsAtTime(t *testing.T) {
+	s, calls := newTestScheduler()
+
+	at := time.Now().Add(50 * time.Millisecond)
+	e, err := s.Add("", at, "/heads.head/set_target", []byte{1, 2})
+	require.NoError(t, err)
+	assert.NotEmpty(t, e.ID)
+	assert.Len(t, s.Pending(), 1)
+
+	select {
+	case c := <-calls:
+		assert.Equal(t, "/heads.head/set_target", c.method)
+		assert.Equal(t, []byte{1, 2}, c.request)
+		assert.False(t, c.at.Before(at))
+	case <-time.After(time.Second):
+		t.Fatal("command didn't run")
+	}
+	assert.Empty(t, s.Pending())
+}
+
+func TestPastRunsNow(t *testing.T) {
+	s, calls := newTestScheduler()
+
+	_, err := s.Add("late", time.Now().Add(-time.Second), "/heads.head/set_actor", nil)
+	require.NoError(t, err)
+
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("command didn't run")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	s, calls := newTestScheduler()
+
+	at := time.Now().Add(50 * time.Millisecond)
+	_, err := s.Add("a", at, "/heads.head/set_target", nil)
+	require.NoError(t, err)
+	_, err = s.Add("b", at, "/heads.head/set_target", nil)
+	require.NoError(t, err)
+	_, err = s.Add("c", at, "/heads.head/set_target", nil)
+	require.NoError(t, err)
+
+	assert.True(t, s.Cancel("a"))
+	assert.False(t, s.Cancel("a"))
+	assert.Equal(t, 2, s.CancelAll())
+
+	select {
+	case c := <-calls:
+		t.Fatalf("cancelled command ran: %s", c.method)
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestRejects(t *testing.T) {
+	s, _ := newTestScheduler()
+	now := time.Now()
+
+	_, err := s.Add("", now, "", nil)
+	assert.Equal(t, ErrNoMethod, err)
+
+	_, err = s.Add("", now, "/heads.scheduler/schedule", nil)
+	assert.Equal(t, ErrRecursive, err)
+
+	_, err = s.Add("", now.Add(MaxAhead+time.Hour), "/heads.head/set_target", nil)
+	assert.Equal(t, ErrTooFar, err)
+
+	_, err = s.Add("x", now.Add(time.Hour), "/heads.head/set_target", nil)
+	require.NoError(t, err)
+	_, err = s.Add("x", now.Add(time.Hour), "/heads.head/set_target", nil)
+	assert.Equal(t, ErrDuplicate, err)
+	s.CancelAll()
+}
+
+func TestPendingOrder(t *testing.T) {
+	s, _ := newTestScheduler()
+	defer s.CancelAll()
+	now := time.Now()
+
+	for _, id := range []string{"3", "1", "2"} {
+		n, _ := time.ParseDuration(id + "h")
+		_, err := s.Add(id, now.Add(n), "/heads.head/set_target", nil)
+		require.NoError(t, err)
+	}
+
+	var ids []string
+	for _, e := range s.Pending() {
+		ids = append(ids, e.ID)
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+}
+
+func TestSharedRegistry(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	assert.NotPanics(t, func() {
+		New(zap.NewNop(), registry, nil)
+		New(zap.NewNop(), registry, nil)
+	}, "schedulers in one process share their metrics")
+}
@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"context"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ScheduleIn and CancelIn are what every build of schedule.proto generates for the
+// requests
+type ScheduleIn interface {
+	GetId() string
+	GetAt() *timestamppb.Timestamp
+	GetMethod() string
+	GetRequest() []byte
+}
+
+type CancelIn interface {
+	GetId() string
+	GetAll() bool
+}
+
+// Messages builds the responses of one build of schedule.proto, so services can serve the
+// scheduler over whichever generated package they use without this module importing it
+type Messages[S, C, L any] struct {
+	Scheduled func(id string, at *timestamppb.Timestamp, method string) S
+	Cancelled func(n int32) C
+	List      func(scheduled []S) L
+}
+
+// Server implements the scheduler service. SI, CI and E are the build's ScheduleIn,
+// CancelIn and Empty, and S, C and L its Scheduled, CancelOut and ScheduledList.
+type Server[SI ScheduleIn, CI CancelIn, E, S, C, L any] struct {
+	s *Scheduler
+	m Messages[S, C, L]
+}
+
+func NewServer[SI ScheduleIn, CI CancelIn, E, S, C, L any](
+	s *Scheduler,
+	m Messages[S, C, L],
+) *Server[SI, CI, E, S, C, L] {
+	return &Server[SI, CI, E, S, C, L]{s: s, m: m}
+}
+
+func (s *Server[SI, CI, E, S, C, L]) Schedule(ctx context.Context, in SI) (S, error) {
+	var none S
+	if err := in.GetAt().CheckValid(); err != nil {
+		return none, ToStatus(in.GetId(), err)
+	}
+
+	e, err := s.s.Add(in.GetId(), in.GetAt().AsTime(), in.GetMethod(), in.GetRequest())
+	if err != nil {
+		return none, ToStatus(in.GetId(), err)
+	}
+	return s.scheduled(e), nil
+}
+
+func (s *Server[SI, CI, E, S, C, L]) Cancel(ctx context.Context, in CI) (C, error) {
+	if in.GetAll() {
+		return s.m.Cancelled(int32(s.s.CancelAll())), nil
+	}
+	if s.s.Cancel(in.GetId()) {
+		return s.m.Cancelled(1), nil
+	}
+	return s.m.Cancelled(0), nil
+}
+
+func (s *Server[SI, CI, E, S, C, L]) List(ctx context.Context, empty E) (L, error) {
+	var result []S
+	for _, e := range s.s.Pending() {
+		result = append(result, s.scheduled(e))
+	}
+	return s.m.List(result), nil
+}
+
+func (s *Server[SI, CI, E, S, C, L]) scheduled(e Entry) S {
+	return s.m.Scheduled(e.ID, timestamppb.New(e.At), e.Method)
+}
@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"testing"
+	"time"
+)
+
+// stand-ins for a generated package's messages
+type (
+	scheduleIn struct {
+		id, method string
+		at         *timestamppb.Timestamp
+	}
+	cancelIn struct {
+		id  string
+		all bool
+	}
+	scheduled struct {
+		id, method string
+		at         time.Time
+	}
+)
+
+func (in *scheduleIn) GetId() string                 { return in.id }
+func (in *scheduleIn) GetAt() *timestamppb.Timestamp { return in.at }
+func (in *scheduleIn) GetMethod() string             { return in.method }
+func (in *scheduleIn) GetRequest() []byte            { return nil }
+func (in *cancelIn) GetId() string                   { return in.id }
+func (in *cancelIn) GetAll() bool                    { return in.all }
+
+func newTestServer() *Server[*scheduleIn, *cancelIn, struct{}, scheduled, int32, []scheduled] {
+	s, _ := newTestScheduler()
+	return NewServer[*scheduleIn, *cancelIn, struct{}](s, Messages[scheduled, int32, []scheduled]{
+		Scheduled: func(id string, at *timestamppb.Timestamp, method string) scheduled {
+			return scheduled{id: id, method: method, at: at.AsTime()}
+		},
+		Cancelled: func(n int32) int32 { return n },
+		List:      func(s []scheduled) []scheduled { return s },
+	})
+}
+
+func TestServer(t *testing.T) {
+	srv := newTestServer()
+	ctx := context.Background()
+	at := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+
+	e, err := srv.Schedule(ctx, &scheduleIn{id: "a", method: "/heads.head/set_target", at: timestamppb.New(at)})
+	require.NoError(t, err)
+	assert.Equal(t, scheduled{id: "a", method: "/heads.head/set_target", at: at}, e)
+
+	_, err = srv.Schedule(ctx, &scheduleIn{id: "b", method: "/heads.head/set_target"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "a missing time is an error")
+
+	_, err = srv.Schedule(ctx, &scheduleIn{id: "b", method: "/heads.head/set_actor", at: timestamppb.New(at)})
+	require.NoError(t, err)
+
+	list, err := srv.List(ctx, struct{}{})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	n, err := srv.Cancel(ctx, &cancelIn{id: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), n)
+
+	n, err = srv.Cancel(ctx, &cancelIn{id: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), n)
+
+	n, err = srv.Cancel(ctx, &cancelIn{all: true})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), n)
+}